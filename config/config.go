@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// File is a config file with flag values. Defaults are used by every command, values of the selected profile
+// override them
+type File struct {
+	Defaults map[string]string            `yaml:"defaults,omitempty"`
+	Profiles map[string]map[string]string `yaml:"profiles,omitempty"`
+}
+
+// DefaultPath returns nfc-cli/config.yaml in the user config directory, i.e. ~/.config/nfc-cli/config.yaml on Linux
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "nfc-cli.yaml"
+	}
+
+	return filepath.Join(dir, "nfc-cli", "config.yaml")
+}
+
+// Load reads config file. Missing file is the same as empty one
+func Load(path string) (File, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return File{}, nil
+	}
+	if err != nil {
+		return File{}, errors.Wrap(err, "Can't read config file")
+	}
+
+	var f File
+	err = yaml.UnmarshalStrict(data, &f)
+	if err != nil {
+		return File{}, errors.Wrapf(err, "Can't parse config file %s", path)
+	}
+
+	return f, nil
+}
+
+// Save writes config file creating its directory. File is readable by the owner only as it can contain passwords
+func (f File) Save(path string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return errors.Wrap(err, "Can't encode config file")
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "Can't create config directory")
+	}
+
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		return errors.Wrap(err, "Can't write config file")
+	}
+
+	return nil
+}
+
+// Values returns defaults merged with values of the profile. Defaults are returned for empty profile
+func (f File) Values(profile string) (map[string]string, error) {
+	values := map[string]string{}
+	for k, v := range f.Defaults {
+		values[k] = v
+	}
+	if len(profile) == 0 {
+		return values, nil
+	}
+
+	p, ok := f.Profiles[profile]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Profile %s is not found. Available profiles: %s", profile, f.profileNames()))
+	}
+	for k, v := range p {
+		values[k] = v
+	}
+
+	return values, nil
+}
+
+// Set sets value of the profile or default value for empty profile. Empty value removes the key
+func (f *File) Set(profile, key, value string) {
+	values := f.Defaults
+	if len(profile) > 0 {
+		values = f.Profiles[profile]
+	}
+
+	if len(value) == 0 {
+		delete(values, key)
+		return
+	}
+
+	if values == nil {
+		values = map[string]string{}
+	}
+	values[key] = value
+
+	if len(profile) == 0 {
+		f.Defaults = values
+		return
+	}
+	if f.Profiles == nil {
+		f.Profiles = map[string]map[string]string{}
+	}
+	f.Profiles[profile] = values
+}
+
+func (f File) profileNames() string {
+	if len(f.Profiles) == 0 {
+		return "none"
+	}
+
+	var names []string
+	for n := range f.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFile_Values(t *testing.T) {
+	f := File{}
+	f.Set("", "host", "10.0.0.1:3011")
+	f.Set("", "timeout", "30")
+	f.Set("line2", "host", "10.0.0.2:3011")
+
+	values, err := f.Values("")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"host": "10.0.0.1:3011", "timeout": "30"}, values)
+
+	values, err = f.Values("line2")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"host": "10.0.0.2:3011", "timeout": "30"}, values)
+
+	_, err = f.Values("line3")
+	assert.EqualError(t, err, "Profile line3 is not found. Available profiles: line2")
+
+	f.Set("", "timeout", "")
+	values, err = f.Values("")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"host": "10.0.0.1:3011"}, values)
+}
+
+func TestLoad(t *testing.T) {
+	f, err := Load("config_test.missing.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, File{}, f)
+}
@@ -0,0 +1,146 @@
+package dump
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/ndef"
+	"strings"
+)
+
+type Family = string
+
+const (
+	FamilyType2   Family = "ntag/ultralight"
+	FamilyClassic Family = "mifare classic"
+	FamilyUnknown Family = "unknown"
+)
+
+// Kind is a memory region category used to tell important changes from user data ones
+type Kind = string
+
+const (
+	KindUid    Kind = "uid"
+	KindLock   Kind = "lock"
+	KindConfig Kind = "config"
+	KindUser   Kind = "user"
+)
+
+// Region is an annotated range of pages
+type Region struct {
+	Page  int
+	Count int
+	Kind  Kind
+	Name  string
+	Notes []string
+}
+
+// Layout is a memory map of the dump
+type Layout struct {
+	Family  Family
+	Model   string
+	Regions []Region
+	// Data is the memory area with TLV blocks which holds NDEF message
+	Data     []byte
+	Warnings []string
+}
+
+// Region returns region the page belongs to
+func (l Layout) Region(page int) (Region, bool) {
+	for _, r := range l.Regions {
+		if page >= r.Page && page < r.Page+r.Count {
+			return r, true
+		}
+	}
+
+	return Region{}, false
+}
+
+// NdefMessage returns NDEF message stored in the NDEF message TLV of the data area
+func (l Layout) NdefMessage() ([]byte, error) {
+	if len(l.Data) == 0 {
+		return nil, errors.New("Tag memory doesn't have NDEF data area")
+	}
+
+	return ndef.UnwrapTlv(l.Data)
+}
+
+// Analyze recognizes tag family and builds memory map of the dump
+func Analyze(d Dump) Layout {
+	product := strings.ToLower(d.Product)
+	switch {
+	case strings.Contains(product, "classic") || d.PageSize() == 16:
+		return analyzeClassic(d)
+	case d.PageSize() == 4:
+		return analyzeType2(d)
+	}
+
+	var regions []Region
+	for _, p := range d.Pages {
+		regions = append(regions, Region{Page: p.Number, Count: 1, Kind: KindUser, Name: p.Info})
+	}
+
+	return Layout{Family: FamilyUnknown, Model: d.Product, Regions: regions, Warnings: []string{"Tag family is not recognized"}}
+}
+
+func (l *Layout) add(page, count int, kind Kind, name string, notes ...string) {
+	l.Regions = append(l.Regions, Region{Page: page, Count: count, Kind: kind, Name: name, Notes: notes})
+}
+
+// lastPage returns number of the last page in the dump
+func lastPage(d Dump) int {
+	last := -1
+	for _, p := range d.Pages {
+		if p.Number > last {
+			last = p.Number
+		}
+	}
+
+	return last
+}
+
+// readArea joins data of pages from first to last skipping pages which are not in the dump
+func readArea(d Dump, first, last int) []byte {
+	var res []byte
+	for i := first; i <= last; i++ {
+		if data, ok := d.Page(i); ok {
+			res = append(res, data...)
+		}
+	}
+
+	return res
+}
+
+// FormatPages joins page numbers into ranges: 1-3, 5
+func FormatPages(pages []int) string {
+	if len(pages) == 0 {
+		return "none"
+	}
+
+	var ranges []string
+	for i := 0; i < len(pages); {
+		j := i
+		for j+1 < len(pages) && pages[j+1] == pages[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, fmt.Sprintf("%d", pages[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", pages[i], pages[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(ranges, ", ")
+}
+
+func bccNote(name string, bcc byte, bytes ...byte) string {
+	expected := byte(0)
+	for _, b := range bytes {
+		expected ^= b
+	}
+	if bcc == expected {
+		return fmt.Sprintf("%s 0x%02X is valid", name, bcc)
+	}
+
+	return fmt.Sprintf("%s 0x%02X is invalid, expected 0x%02X", name, bcc, expected)
+}
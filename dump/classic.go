@@ -0,0 +1,159 @@
+package dump
+
+import (
+	"fmt"
+)
+
+// NDEF application ID in the MIFARE application directory
+const madNdefAid uint16 = 0x03E1
+
+var classicModels = map[int]string{
+	20:  "MIFARE Classic Mini",
+	64:  "MIFARE Classic 1K",
+	128: "MIFARE Classic 2K",
+	256: "MIFARE Classic 4K",
+}
+
+// data block access conditions indexed by C1C2C3 bits
+var classicDataAccess = [8]string{
+	"read AB, write AB, increment AB, decrement AB",
+	"read AB, decrement AB",
+	"read AB",
+	"read B, write B",
+	"read AB, write B",
+	"read B",
+	"read AB, write B, increment B, decrement AB",
+	"never",
+}
+
+// sector trailer access conditions indexed by C1C2C3 bits
+var classicTrailerAccess = [8]string{
+	"key A write A, access bits read A, key B read A write A",
+	"key A write A, access bits read A write A, key B read A write A",
+	"access bits read A, key B read A",
+	"key A write B, access bits read AB write B, key B write B",
+	"key A write B, access bits read AB, key B write B",
+	"access bits read AB write B",
+	"access bits read AB",
+	"access bits read AB",
+}
+
+// classicSector returns sector of the block and number of blocks in it.
+// First 32 sectors have 4 blocks and sectors of 4K tag after them have 16 blocks
+func classicSector(block int) (sector, first, count int) {
+	if block < 128 {
+		return block / 4, block / 4 * 4, 4
+	}
+
+	return 32 + (block-128)/16, 128 + (block-128)/16*16, 16
+}
+
+func analyzeClassic(d Dump) Layout {
+	blocks := lastPage(d) + 1
+	l := Layout{Family: FamilyClassic, Model: d.Product}
+	if len(l.Model) == 0 {
+		l.Model = classicModels[blocks]
+	}
+	if _, ok := classicModels[blocks]; !ok {
+		l.Warnings = append(l.Warnings, fmt.Sprintf("Unexpected number of blocks %d", blocks))
+	}
+
+	block0, _ := d.Page(0)
+	var manufacturer []string
+	if len(block0) == 16 {
+		if len(d.Uid) == 7 {
+			manufacturer = append(manufacturer, fmt.Sprintf("UID % X", block0[:7]))
+		} else {
+			manufacturer = append(manufacturer, fmt.Sprintf("UID % X", block0[:4]), bccNote("BCC", block0[4], block0[:4]...))
+		}
+	}
+	l.add(0, 1, KindUid, "Manufacturer block", manufacturer...)
+
+	// MAD is present when DA bit of the general purpose byte in the sector 0 trailer is set
+	trailer0, _ := d.Page(3)
+	mad := len(trailer0) == 16 && trailer0[9]&0x80 != 0
+	var ndefSectors []int
+	if mad {
+		mad1 := readArea(d, 1, 2)
+		if len(mad1) == 32 {
+			for sector := 1; sector < 16; sector++ {
+				aid := uint16(mad1[sector*2]) | uint16(mad1[sector*2+1])<<8
+				if aid == madNdefAid {
+					ndefSectors = append(ndefSectors, sector)
+				}
+			}
+		}
+		l.add(1, 2, KindConfig, "MIFARE application directory", "NDEF sectors: "+FormatPages(ndefSectors))
+	}
+
+	ndefSector := make(map[int]bool, len(ndefSectors))
+	for _, s := range ndefSectors {
+		ndefSector[s] = true
+	}
+
+	for block := 0; block < blocks; {
+		sector, first, count := classicSector(block)
+		trailer := first + count - 1
+		data, _ := d.Page(trailer)
+		access, notes := classicTrailerNotes(data, count)
+
+		for b := first; b < trailer; b++ {
+			if b == 0 || (mad && (b == 1 || b == 2)) {
+				continue
+			}
+			name := fmt.Sprintf("Sector %d data", sector)
+			if ndefSector[sector] {
+				name = fmt.Sprintf("Sector %d NDEF data", sector)
+				l.Data = append(l.Data, readArea(d, b, b)...)
+			}
+			var blockNotes []string
+			if access != nil {
+				blockNotes = append(blockNotes, "Access: "+access[b-first])
+			}
+			l.add(b, 1, KindUser, name, blockNotes...)
+		}
+		l.add(trailer, 1, KindConfig, fmt.Sprintf("Sector %d trailer", sector), notes...)
+
+		block = first + count
+	}
+
+	return l
+}
+
+// classicTrailerNotes decodes sector trailer and returns access conditions of the sector data blocks
+func classicTrailerNotes(data []byte, blocks int) ([]string, []string) {
+	if len(data) != 16 {
+		return nil, nil
+	}
+
+	notes := []string{
+		fmt.Sprintf("Key A % X, key B % X, general purpose byte 0x%02X", data[:6], data[10:16], data[9]),
+	}
+
+	b6, b7, b8 := data[6], data[7], data[8]
+	if b6&0x0F != ^b7>>4&0x0F || b6>>4 != ^b8&0x0F || b7&0x0F != ^b8>>4&0x0F {
+		return nil, append(notes, fmt.Sprintf("Access bits % X are invalid", data[6:9]))
+	}
+
+	// condition bits C1, C2, C3 of the access group
+	cond := func(group uint) int {
+		c1 := int(b7 >> (4 + group) & 1)
+		c2 := int(b8 >> group & 1)
+		c3 := int(b8 >> (4 + group) & 1)
+		return c1<<2 | c2<<1 | c3
+	}
+
+	notes = append(notes, fmt.Sprintf("Access bits % X, trailer: %s", data[6:9], classicTrailerAccess[cond(3)]))
+
+	// sectors with 16 blocks have access groups of 5 blocks
+	access := make([]string, blocks-1)
+	for i := range access {
+		group := i
+		if blocks == 16 {
+			group = i / 5
+		}
+		access[i] = classicDataAccess[cond(uint(group))]
+	}
+
+	return access, notes
+}
@@ -0,0 +1,73 @@
+package dump
+
+import (
+	"bytes"
+	"sort"
+)
+
+// PageDiff is a page which differs in two dumps. Data is nil when the page is missing in the dump
+type PageDiff struct {
+	Page   int
+	Kind   Kind
+	Region string
+	A      []byte
+	B      []byte
+	// Changed holds offsets of changed bytes
+	Changed []int
+}
+
+// Diff aligns pages of two dumps by their numbers and returns pages which differ.
+// Pages are classified by memory layout of the first dump
+func Diff(a, b Dump) []PageDiff {
+	layout := Analyze(a)
+	if layout.Family == FamilyUnknown {
+		layout = Analyze(b)
+	}
+
+	return diffLayout(a, b, layout)
+}
+
+func diffLayout(a, b Dump, layout Layout) []PageDiff {
+	numbers := make(map[int]bool)
+	for _, p := range a.Pages {
+		numbers[p.Number] = true
+	}
+	for _, p := range b.Pages {
+		numbers[p.Number] = true
+	}
+
+	var diffs []PageDiff
+	for n := range numbers {
+		dataA, _ := a.Page(n)
+		dataB, _ := b.Page(n)
+		if bytes.Equal(dataA, dataB) {
+			continue
+		}
+
+		diff := PageDiff{Page: n, Kind: KindUser, A: dataA, B: dataB, Changed: changedBytes(dataA, dataB)}
+		if r, ok := layout.Region(n); ok {
+			diff.Kind = r.Kind
+			diff.Region = r.Name
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Page < diffs[j].Page })
+
+	return diffs
+}
+
+func changedBytes(a, b []byte) []int {
+	size := len(a)
+	if len(b) > size {
+		size = len(b)
+	}
+
+	var res []int
+	for i := 0; i < size; i++ {
+		if i >= len(a) || i >= len(b) || a[i] != b[i] {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
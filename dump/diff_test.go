@@ -0,0 +1,25 @@
+package dump
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := newDump("NTAG213", nil, 4, ntag213Memory())
+
+	assert.Empty(t, Diff(a, a))
+
+	memory := ntag213Memory()
+	memory[2*4+2] = 0xF0
+	memory[5*4+1] = 0x42
+	memory[41*4+3] = 0x04
+	b := newDump("NTAG213", nil, 4, memory[:44*4])
+
+	assert.Equal(t, []PageDiff{
+		{Page: 2, Kind: KindLock, Region: "BCC1, internal, static lock bytes", A: []byte{0x00, 0x48, 0x00, 0x00}, B: []byte{0x00, 0x48, 0xF0, 0x00}, Changed: []int{2}},
+		{Page: 5, Kind: KindUser, Region: "User memory", A: []byte{0x08, 0x55, 0x04, 0x74}, B: []byte{0x08, 0x42, 0x04, 0x74}, Changed: []int{1}},
+		{Page: 41, Kind: KindConfig, Region: "CFG0", A: []byte{0x04, 0x00, 0x00, 0xFF}, B: []byte{0x04, 0x00, 0x00, 0x04}, Changed: []int{3}},
+		{Page: 44, Kind: KindConfig, Region: "PACK", A: []byte{0x00, 0x00, 0x00, 0x00}, B: nil, Changed: []int{0, 1, 2, 3}},
+	}, Diff(a, b))
+}
@@ -0,0 +1,195 @@
+package dump
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/utils"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ErrNoDump is returned for job runs without get dump step
+var ErrNoDump = errors.New("Job run doesn't contain dump output")
+
+// Dump is a tag memory read by the get dump command
+type Dump struct {
+	Uid     []byte
+	Product string
+	Pages   []Page
+}
+
+// Page is a single page or block of tag memory
+type Page struct {
+	Number int
+	Data   []byte
+	Info   string
+}
+
+type runResource struct {
+	Tag struct {
+		Uid     string `json:"uid"`
+		Product string `json:"product"`
+	} `json:"tag"`
+	Results []struct {
+		Command string                          `json:"command"`
+		Output  apiModels.GetDumpOutputResource `json:"output"`
+	} `json:"results"`
+}
+
+// FromPages builds dump from pages returned by the server. Page numbers are hex strings,
+// pages with number that can't be parsed are numbered by their position
+func FromPages(uid []byte, product string, pages []apiModels.PageDumpResource) (Dump, error) {
+	d := Dump{Uid: uid, Product: product, Pages: make([]Page, len(pages))}
+	for i, p := range pages {
+		data, err := utils.ParseHexString(p.Data)
+		if err != nil {
+			return Dump{}, errors.Wrapf(err, "Page %s", p.Page)
+		}
+
+		number, err := strconv.ParseInt(strings.TrimPrefix(strings.TrimSpace(p.Page), "0x"), 16, 32)
+		if err != nil {
+			number = int64(i)
+		}
+
+		d.Pages[i] = Page{Number: int(number), Data: data, Info: p.Info}
+	}
+
+	return d, nil
+}
+
+// FromRun extracts dump from job run resource encoded as JSON
+func FromRun(data []byte) (Dump, error) {
+	var run runResource
+	err := json.Unmarshal(data, &run)
+	if err != nil {
+		return Dump{}, errors.Wrap(err, "Can't parse job run")
+	}
+
+	uid, err := base64.StdEncoding.DecodeString(run.Tag.Uid)
+	if err != nil {
+		return Dump{}, errors.Wrap(err, "Can't decode tag UID. It should be base64 encoded")
+	}
+
+	for _, r := range run.Results {
+		if r.Command == apiModels.CommandGetDump.String() && len(r.Output.MemoryDump) > 0 {
+			return FromPages(uid, run.Tag.Product, r.Output.MemoryDump)
+		}
+	}
+
+	return Dump{}, ErrNoDump
+}
+
+// FromRunData extracts dump from job run received in the WS event
+func FromRunData(data interface{}) (Dump, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return Dump{}, errors.Wrap(err, "Can't encode job run")
+	}
+
+	return FromRun(encoded)
+}
+
+// ReadFile reads dumps from file. Format is detected by file extension, JSON files and files with other extensions
+// are read as job runs written by the dump command with output flag or Proxmark JSON dumps
+func ReadFile(filename string) ([]Dump, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read dump file")
+	}
+
+	format := FormatFromFilename(filename)
+	// job runs are written by the dump command to output files with any extension
+	if format != FormatBin && isJsonData(data) {
+		format = FormatRun
+	}
+
+	dumps, err := Decode(data, format)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse dump file")
+	}
+	if len(dumps) == 0 {
+		return nil, errors.New(fmt.Sprintf("File %s doesn't contain any dumps", filename))
+	}
+
+	return dumps, nil
+}
+
+// ReadSingleFile reads the file which should contain a single dump, i.e. when dumps are compared
+func ReadSingleFile(filename string) (Dump, error) {
+	dumps, err := ReadFile(filename)
+	if err != nil {
+		return Dump{}, err
+	}
+	if len(dumps) > 1 {
+		return Dump{}, errors.New(fmt.Sprintf("File %s contains %d dumps, a file with a single dump should be used", filename, len(dumps)))
+	}
+
+	return dumps[0], nil
+}
+
+// decodeRuns reads job runs one per line or JSON array of job runs printed in json format.
+// Runs without dump are skipped
+func decodeRuns(data []byte) ([]Dump, error) {
+	var runs []json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var v json.RawMessage
+		err := decoder.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.HasPrefix(bytes.TrimSpace(v), []byte("[")) {
+			var list []json.RawMessage
+			if err = json.Unmarshal(v, &list); err != nil {
+				return nil, err
+			}
+			runs = append(runs, list...)
+			continue
+		}
+		runs = append(runs, v)
+	}
+
+	var dumps []Dump
+	for i, r := range runs {
+		d, err := FromRun(r)
+		if err != nil {
+			if err == ErrNoDump {
+				continue
+			}
+			return nil, errors.Wrapf(err, "Run %d", i+1)
+		}
+		dumps = append(dumps, d)
+	}
+
+	return dumps, nil
+}
+
+// Page returns page data by its number
+func (d Dump) Page(number int) ([]byte, bool) {
+	for _, p := range d.Pages {
+		if p.Number == number {
+			return p.Data, true
+		}
+	}
+
+	return nil, false
+}
+
+// PageSize returns size of the first page. NFC Forum type 2 tags have 4 byte pages and MIFARE Classic has 16 byte blocks
+func (d Dump) PageSize() int {
+	if len(d.Pages) == 0 {
+		return 0
+	}
+
+	return len(d.Pages[0].Data)
+}
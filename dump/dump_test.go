@@ -0,0 +1,172 @@
+package dump
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newDump(product string, uid []byte, pageSize int, memory []byte) Dump {
+	d := Dump{Uid: uid, Product: product}
+	for i := 0; i*pageSize < len(memory); i++ {
+		d.Pages = append(d.Pages, Page{Number: i, Data: memory[i*pageSize : (i+1)*pageSize]})
+	}
+
+	return d
+}
+
+func ntag213Memory() []byte {
+	memory := make([]byte, 45*4)
+	copy(memory, []byte{
+		0x04, 0xA2, 0xB3, 0x88 ^ 0x04 ^ 0xA2 ^ 0xB3,
+		0xC4, 0xD5, 0xE6, 0xF7,
+		0xC4 ^ 0xD5 ^ 0xE6 ^ 0xF7, 0x48, 0x00, 0x00,
+		0xE1, 0x10, 0x12, 0x00,
+		0x03, 0x0C, 0xD1, 0x01, 0x08, 0x55, 0x04, 't', 'a', 'g', 'l', '.', 'm', 'e', 0xFE,
+	})
+	copy(memory[40*4:], []byte{0x01, 0x00, 0x00, 0xBD, 0x04, 0x00, 0x00, 0xFF, 0x00, 0x05})
+
+	return memory
+}
+
+func TestAnalyze_Type2(t *testing.T) {
+	d := newDump("NTAG213", []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, 4, ntag213Memory())
+
+	l := Analyze(d)
+	assert.Equal(t, FamilyType2, l.Family)
+	assert.Equal(t, "NTAG213", l.Model)
+	assert.Empty(t, l.Warnings)
+
+	r, ok := l.Region(0)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"UID 04 A2 B3 C4 D5 E6 F7", "BCC0 0x9D is valid"}, r.Notes)
+
+	r, _ = l.Region(3)
+	assert.Equal(t, []string{"NDEF mapping version 1.0", "Data area size 144 bytes", "Read access granted, write access granted"}, r.Notes)
+
+	r, _ = l.Region(20)
+	assert.Equal(t, Region{Page: 4, Count: 36, Kind: KindUser, Name: "User memory", Notes: []string{"144 bytes, NDEF TLV area"}}, r)
+
+	r, _ = l.Region(40)
+	assert.Equal(t, KindLock, r.Kind)
+	assert.Equal(t, "Locked pages: 16-17", r.Notes[0])
+
+	r, _ = l.Region(41)
+	assert.Equal(t, "AUTH0 0xFF: password protection is disabled", r.Notes[1])
+
+	message, err := l.NdefMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xD1, 0x01, 0x08, 0x55, 0x04, 't', 'a', 'g', 'l', '.', 'm', 'e'}, message)
+
+	// model is recognized by memory size and missing pages are reported
+	d = newDump("", nil, 4, ntag213Memory()[:44*4])
+	l = Analyze(d)
+	assert.Equal(t, "NTAG213", l.Model)
+	assert.Equal(t, []string{"Dump is incomplete: 44 of 45 pages are read"}, l.Warnings)
+}
+
+func TestAnalyze_Classic(t *testing.T) {
+	memory := make([]byte, 64*16)
+	copy(memory, []byte{0x11, 0x22, 0x33, 0x44, 0x44, 0x08, 0x04, 0x00})
+	// MAD with NDEF application in sector 1
+	copy(memory[16:], []byte{0x00, 0x01, 0xE1, 0x03})
+	for sector := 0; sector < 16; sector++ {
+		copy(memory[(sector*4+3)*16:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x07, 0x80, 0x69, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	}
+	copy(memory[3*16:], []byte{0xA0, 0xA1, 0xA2, 0xA3, 0xA4, 0xA5, 0x78, 0x77, 0x88, 0xC1})
+	copy(memory[4*16:], []byte{0x03, 0x03, 0xD0, 0x00, 0x00, 0xFE})
+	copy(memory[7*16:], []byte{0xD3, 0xF7, 0xD3, 0xF7, 0xD3, 0xF7, 0x7F, 0x07, 0x88, 0x40})
+	memory[63*16+7] = 0x00
+
+	l := Analyze(newDump("", []byte{0x11, 0x22, 0x33, 0x44}, 16, memory))
+	assert.Equal(t, FamilyClassic, l.Family)
+	assert.Equal(t, "MIFARE Classic 1K", l.Model)
+
+	r, _ := l.Region(0)
+	assert.Equal(t, []string{"UID 11 22 33 44", "BCC 0x44 is valid"}, r.Notes)
+
+	r, _ = l.Region(2)
+	assert.Equal(t, Region{Page: 1, Count: 2, Kind: KindConfig, Name: "MIFARE application directory", Notes: []string{"NDEF sectors: 1"}}, r)
+
+	r, _ = l.Region(3)
+	assert.Equal(t, "Access bits 78 77 88, trailer: key A write B, access bits read AB write B, key B write B", r.Notes[1])
+
+	r, _ = l.Region(5)
+	assert.Equal(t, Region{Page: 5, Count: 1, Kind: KindUser, Name: "Sector 1 NDEF data", Notes: []string{"Access: read AB, write AB, increment AB, decrement AB"}}, r)
+
+	r, _ = l.Region(63)
+	assert.Equal(t, "Access bits FF 00 80 are invalid", r.Notes[1])
+
+	message, err := l.NdefMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xD0, 0x00, 0x00}, message)
+}
+
+func TestReadFile(t *testing.T) {
+	run := map[string]interface{}{
+		"run_id": "run",
+		"tag":    map[string]interface{}{"uid": "BKKzxNXm9w==", "product": "NTAG213"},
+		"results": []interface{}{
+			map[string]interface{}{"command": apiModels.CommandGetTags.String()},
+			map[string]interface{}{
+				"command": apiModels.CommandGetDump.String(),
+				"output": apiModels.GetDumpOutputResource{MemoryDump: []apiModels.PageDumpResource{
+					{Page: "00", Data: "04 A2 B3 9D", Info: "UID"},
+					{Page: "0A", Data: "00000000"},
+				}},
+			},
+		},
+	}
+	data, err := json.Marshal(run)
+	assert.Nil(t, err)
+
+	// runs are detected by content in files with extension of text formats
+	file, err := ioutil.TempFile("", "dump*.txt")
+	assert.Nil(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.Write(append(append(data, '\n'), data...))
+	assert.Nil(t, err)
+	assert.Nil(t, file.Close())
+
+	dumps, err := ReadFile(file.Name())
+	assert.Nil(t, err)
+	assert.Len(t, dumps, 2)
+	_, err = ReadSingleFile(file.Name())
+	assert.EqualError(t, err, "File "+file.Name()+" contains 2 dumps, a file with a single dump should be used")
+	assert.Equal(t, Dump{
+		Uid:     []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7},
+		Product: "NTAG213",
+		Pages: []Page{
+			{Number: 0, Data: []byte{0x04, 0xA2, 0xB3, 0x9D}, Info: "UID"},
+			{Number: 10, Data: []byte{0x00, 0x00, 0x00, 0x00}},
+		},
+	}, dumps[0])
+
+	// runs printed in json format
+	err = ioutil.WriteFile(file.Name(), []byte("[{\"run_id\": \"run\", \"tag\": {}}]"), 0644)
+	assert.Nil(t, err)
+	_, err = ReadFile(file.Name())
+	assert.EqualError(t, err, "File "+file.Name()+" doesn't contain any dumps")
+}
+
+func TestPackPage(t *testing.T) {
+	page, ok := PackPage("NTAG213")
+	assert.True(t, ok)
+	assert.Equal(t, 0x2C, page)
+
+	page, ok = PackPage("NXP NTAG216")
+	assert.True(t, ok)
+	assert.Equal(t, 0xE6, page)
+
+	page, ok = PackPage("MF0UL21")
+	assert.True(t, ok)
+	assert.Equal(t, 0x28, page)
+
+	_, ok = PackPage("Mifare Ultralight")
+	assert.False(t, ok)
+	_, ok = PackPage("Mifare Ultralight EV1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,365 @@
+package dump
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/utils"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Format is a dump file format
+type Format = string
+
+const (
+	// FormatRun is JSON encoded job run written by commands with output flag
+	FormatRun      Format = "json"
+	FormatBin      Format = "bin"
+	FormatHex      Format = "hex"
+	FormatEml      Format = "eml"
+	FormatProxmark Format = "proxmark"
+	FormatFlipper  Format = "flipper"
+)
+
+var Formats = []Format{FormatRun, FormatBin, FormatHex, FormatEml, FormatProxmark, FormatFlipper}
+
+// FormatFromFilename detects format by file extension. Job run format is returned for unknown extensions
+func FormatFromFilename(filename string) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".bin", ".mfd":
+		return FormatBin
+	case ".hex", ".txt":
+		return FormatHex
+	case ".eml":
+		return FormatEml
+	case ".nfc":
+		return FormatFlipper
+	}
+
+	return FormatRun
+}
+
+// Encode writes dump in the file format. Job run format can't be encoded as the dump doesn't keep the run
+func Encode(d Dump, f Format) ([]byte, error) {
+	switch f {
+	case FormatBin:
+		return d.Memory(), nil
+	case FormatHex, FormatEml:
+		layout := "% X\n"
+		if f == FormatEml {
+			layout = "%X\n"
+		}
+		var buf bytes.Buffer
+		for _, p := range d.Pages {
+			fmt.Fprintf(&buf, layout, p.Data)
+		}
+		return buf.Bytes(), nil
+	case FormatProxmark:
+		return encodeProxmark(d)
+	case FormatFlipper:
+		return encodeFlipper(d), nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("Dump can't be encoded in %s format", f))
+}
+
+// Decode reads dumps from the file content. Dumps in job run format are detected by content,
+// as Proxmark JSON files have the same extension
+func Decode(data []byte, f Format) ([]Dump, error) {
+	switch f {
+	case FormatBin:
+		return []Dump{fromMemory(data, memoryPageSize(len(data)))}, nil
+	case FormatHex, FormatEml:
+		d, err := decodeHexLines(data)
+		return []Dump{d}, err
+	case FormatFlipper:
+		d, err := decodeFlipper(data)
+		return []Dump{d}, err
+	case FormatProxmark:
+		d, err := decodeProxmark(data)
+		return []Dump{d}, err
+	case FormatRun:
+		if isProxmark(data) {
+			d, err := decodeProxmark(data)
+			return []Dump{d}, err
+		}
+		return decodeRuns(data)
+	}
+
+	return nil, errors.New(fmt.Sprintf("Unknown dump format %s", f))
+}
+
+// Memory returns data of all pages. Pages which are not in the dump are filled with zeros
+func (d Dump) Memory() []byte {
+	size := d.PageSize()
+	res := make([]byte, (lastPage(d)+1)*size)
+	for _, p := range d.Pages {
+		copy(res[p.Number*size:], p.Data)
+	}
+
+	return res
+}
+
+// memoryPageSize guesses page size by memory size. MIFARE Classic memory is 320, 1024, 2048 or 4096 bytes
+func memoryPageSize(size int) int {
+	switch size {
+	case 320, 1024, 2048, 4096:
+		return 16
+	}
+
+	return 4
+}
+
+func fromMemory(memory []byte, pageSize int) Dump {
+	d := Dump{}
+	for i := 0; i*pageSize < len(memory); i++ {
+		end := (i + 1) * pageSize
+		if end > len(memory) {
+			end = len(memory)
+		}
+		d.Pages = append(d.Pages, Page{Number: i, Data: memory[i*pageSize : end]})
+	}
+	d.Uid = uidFromPages(d)
+
+	return d
+}
+
+// uidFromPages reads UID from the first pages of the memory
+func uidFromPages(d Dump) []byte {
+	page0, _ := d.Page(0)
+	switch len(page0) {
+	case 16:
+		return append([]byte{}, page0[:4]...)
+	case 4:
+		page1, ok := d.Page(1)
+		if !ok || len(page1) != 4 {
+			return nil
+		}
+		return append(append([]byte{}, page0[:3]...), page1...)
+	}
+
+	return nil
+}
+
+// decodeHexLines reads one page per line. Empty lines and lines starting with # are skipped
+func decodeHexLines(data []byte) (Dump, error) {
+	var memory []byte
+	pageSize := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		s := strings.TrimSpace(scanner.Text())
+		if len(s) == 0 || strings.HasPrefix(s, "#") {
+			continue
+		}
+
+		page, err := utils.ParseHexString(s)
+		if err != nil {
+			return Dump{}, errors.Wrapf(err, "Line %d", line)
+		}
+		if pageSize == 0 {
+			pageSize = len(page)
+		}
+		if len(page) != pageSize {
+			return Dump{}, errors.New(fmt.Sprintf("Line %d: page size is %d bytes, expected %d bytes", line, len(page), pageSize))
+		}
+		memory = append(memory, page...)
+	}
+	if pageSize == 0 {
+		return Dump{}, errors.New("Dump file is empty")
+	}
+
+	return fromMemory(memory, pageSize), nil
+}
+
+type proxmarkFile struct {
+	Created  string `json:"Created"`
+	FileType string `json:"FileType"`
+	Card     struct {
+		UID string `json:"UID"`
+	} `json:"Card"`
+	Blocks map[string]string `json:"blocks"`
+}
+
+// isJsonData reports whether data is JSON object, array or JSON lines rather than text dump format
+func isJsonData(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+func isProxmark(data []byte) bool {
+	var f proxmarkFile
+	return json.Unmarshal(data, &f) == nil && len(f.Blocks) > 0
+}
+
+func encodeProxmark(d Dump) ([]byte, error) {
+	f := proxmarkFile{Created: "nfc-cli", FileType: "mfu", Blocks: make(map[string]string, len(d.Pages))}
+	if d.PageSize() == 16 {
+		f.FileType = "mfcard"
+	}
+	f.Card.UID = fmt.Sprintf("%X", d.Uid)
+	for _, p := range d.Pages {
+		f.Blocks[strconv.Itoa(p.Number)] = fmt.Sprintf("%X", p.Data)
+	}
+
+	return json.MarshalIndent(f, "", "  ")
+}
+
+func decodeProxmark(data []byte) (Dump, error) {
+	var f proxmarkFile
+	err := json.Unmarshal(data, &f)
+	if err != nil {
+		return Dump{}, errors.Wrap(err, "Can't parse Proxmark dump")
+	}
+
+	d := Dump{}
+	d.Uid, err = utils.ParseHexString(f.Card.UID)
+	if err != nil {
+		return Dump{}, errors.Wrap(err, "Can't parse card UID")
+	}
+
+	for n, block := range f.Blocks {
+		number, err := strconv.Atoi(n)
+		if err != nil {
+			return Dump{}, errors.New(fmt.Sprintf("Wrong block number \"%s\"", n))
+		}
+		data, err := utils.ParseHexString(block)
+		if err != nil {
+			return Dump{}, errors.Wrapf(err, "Block %d", number)
+		}
+		d.Pages = append(d.Pages, Page{Number: number, Data: data})
+	}
+	sort.Slice(d.Pages, func(i, j int) bool { return d.Pages[i].Number < d.Pages[j].Number })
+
+	if len(d.Uid) == 0 {
+		d.Uid = uidFromPages(d)
+	}
+
+	return d, nil
+}
+
+// flipperTypes maps models recognized by analyzer to NTAG/Ultralight types of Flipper files
+var flipperTypes = map[int]string{
+	16:  "Mifare Ultralight",
+	20:  "Mifare Ultralight 11",
+	41:  "Mifare Ultralight 21",
+	45:  "NTAG213",
+	135: "NTAG215",
+	231: "NTAG216",
+}
+
+var flipperClassicTypes = map[int]string{
+	20:  "MINI",
+	64:  "1K",
+	128: "2K",
+	256: "4K",
+}
+
+func encodeFlipper(d Dump) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("Filetype: Flipper NFC device\nVersion: 4\n")
+
+	pages := lastPage(d) + 1
+	if d.PageSize() == 16 {
+		atqa, sak := classicAtqaSak(d, pages)
+		fmt.Fprintf(&buf, "Device type: Mifare Classic\nUID: % X\nATQA: % X\nSAK: %02X\n", d.Uid, atqa, sak)
+		fmt.Fprintf(&buf, "Mifare Classic type: %s\nData format version: 2\n", flipperClassicTypes[pages])
+		for _, p := range d.Pages {
+			fmt.Fprintf(&buf, "Block %d: % X\n", p.Number, p.Data)
+		}
+		return buf.Bytes()
+	}
+
+	fmt.Fprintf(&buf, "Device type: NTAG/Ultralight\nUID: % X\nATQA: 00 44\nSAK: 00\n", d.Uid)
+	ulType, ok := flipperTypes[pages]
+	if !ok {
+		ulType = "Mifare Ultralight"
+	}
+	fmt.Fprintf(&buf, "Data format version: 2\nNTAG/Ultralight type: %s\n", ulType)
+	// signature, version and counters are not in the dump, Flipper requires them to load the file
+	fmt.Fprintf(&buf, "Signature: % X\nMifare version: % X\n", make([]byte, 32), make([]byte, 8))
+	for i := 0; i < 3; i++ {
+		fmt.Fprintf(&buf, "Counter %d: 0\nTearing %d: 00\n", i, i)
+	}
+	fmt.Fprintf(&buf, "Pages total: %d\nPages read: %d\n", pages, len(d.Pages))
+	for _, p := range d.Pages {
+		fmt.Fprintf(&buf, "Page %d: % X\n", p.Number, p.Data)
+	}
+
+	return buf.Bytes()
+}
+
+// classicAtqaSak returns ATQA and SAK of MIFARE Classic tag. They are read from manufacturer block of tags with 4 bytes UID,
+// otherwise they are set by memory size and UID length
+func classicAtqaSak(d Dump, pages int) ([]byte, byte) {
+	block0, _ := d.Page(0)
+	if len(block0) == 16 && len(d.Uid) == 4 {
+		return []byte{block0[7], block0[6]}, block0[5]
+	}
+
+	atqa, sak := byte(0x04), byte(0x08)
+	switch pages {
+	case 20:
+		sak = 0x09
+	case 128:
+		sak = 0x10
+	case 256:
+		atqa, sak = 0x02, 0x18
+	}
+	if len(d.Uid) == 7 {
+		atqa |= 0x40
+	}
+
+	return []byte{0x00, atqa}, sak
+}
+
+// decodeFlipper reads UID and pages or blocks of Flipper NFC file. Blocks with unknown bytes marked as ?? are skipped
+func decodeFlipper(data []byte) (Dump, error) {
+	d := Dump{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 || strings.HasPrefix(parts[0], "#") {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+
+		switch {
+		case key == "Filetype" && value != "Flipper NFC device":
+			return Dump{}, errors.New(fmt.Sprintf("Line %d: file type \"%s\" is not supported", line, value))
+		case key == "UID":
+			uid, err := utils.ParseHexString(value)
+			if err != nil {
+				return Dump{}, errors.Wrapf(err, "Line %d", line)
+			}
+			d.Uid = uid
+		case key == "NTAG/Ultralight type":
+			d.Product = value
+		case key == "Mifare Classic type":
+			d.Product = "Mifare Classic " + value
+		case strings.HasPrefix(key, "Page ") || strings.HasPrefix(key, "Block "):
+			if strings.Contains(value, "??") {
+				continue
+			}
+			number, err := strconv.Atoi(key[strings.Index(key, " ")+1:])
+			if err != nil {
+				return Dump{}, errors.New(fmt.Sprintf("Line %d: wrong page number", line))
+			}
+			page, err := utils.ParseHexString(value)
+			if err != nil {
+				return Dump{}, errors.Wrapf(err, "Line %d", line)
+			}
+			d.Pages = append(d.Pages, Page{Number: number, Data: page})
+		}
+	}
+
+	if len(d.Pages) == 0 {
+		return Dump{}, errors.New("Flipper file doesn't contain any pages")
+	}
+
+	return d, nil
+}
@@ -0,0 +1,77 @@
+package dump
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	d := newDump("", []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, 4, ntag213Memory()[:8])
+
+	data, err := Encode(d, FormatHex)
+	assert.Nil(t, err)
+	assert.Equal(t, "04 A2 B3 9D\nC4 D5 E6 F7\n", string(data))
+
+	data, err = Encode(d, FormatEml)
+	assert.Nil(t, err)
+	assert.Equal(t, "04A2B39D\nC4D5E6F7\n", string(data))
+
+	data, err = Encode(d, FormatProxmark)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"Created": "nfc-cli", "FileType": "mfu", "Card": {"UID": "04A2B3C4D5E6F7"}, "blocks": {"0": "04A2B39D", "1": "C4D5E6F7"}}`, string(data))
+
+	data, err = Encode(d, FormatFlipper)
+	assert.Nil(t, err)
+	assert.Equal(t, "Filetype: Flipper NFC device\nVersion: 4\nDevice type: NTAG/Ultralight\nUID: 04 A2 B3 C4 D5 E6 F7\nATQA: 00 44\nSAK: 00\n"+
+		"Data format version: 2\nNTAG/Ultralight type: Mifare Ultralight\n"+
+		"Signature: 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00\nMifare version: 00 00 00 00 00 00 00 00\n"+
+		"Counter 0: 0\nTearing 0: 00\nCounter 1: 0\nTearing 1: 00\nCounter 2: 0\nTearing 2: 00\n"+
+		"Pages total: 2\nPages read: 2\nPage 0: 04 A2 B3 9D\nPage 1: C4 D5 E6 F7\n", string(data))
+
+	classic := Dump{Uid: []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, Pages: []Page{{Number: 63, Data: make([]byte, 16)}}}
+	data, err = Encode(classic, FormatFlipper)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), "UID: 04 A2 B3 C4 D5 E6 F7\nATQA: 00 44\nSAK: 08\nMifare Classic type: 1K\n")
+
+	_, err = Encode(d, FormatRun)
+	assert.EqualError(t, err, "Dump can't be encoded in json format")
+}
+
+func TestDecode(t *testing.T) {
+	d := newDump("", []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, 4, ntag213Memory())
+
+	for _, f := range []Format{FormatBin, FormatHex, FormatEml, FormatProxmark} {
+		data, err := Encode(d, f)
+		assert.Nil(t, err)
+		decoded, err := Decode(data, f)
+		assert.Nil(t, err, f)
+		assert.Equal(t, []Dump{d}, decoded, f)
+	}
+
+	data, err := Encode(d, FormatFlipper)
+	assert.Nil(t, err)
+	decoded, err := Decode(data, FormatFlipper)
+	assert.Nil(t, err)
+	assert.Equal(t, "NTAG213", decoded[0].Product)
+	assert.Equal(t, d.Pages, decoded[0].Pages)
+
+	// Proxmark JSON dumps are detected by content
+	data, err = Encode(d, FormatProxmark)
+	assert.Nil(t, err)
+	decoded, err = Decode(data, FormatFromFilename("dump.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Dump{d}, decoded)
+
+	// unknown bytes of Flipper files are skipped
+	decoded, err = Decode([]byte("Filetype: Flipper NFC device\nUID: 11 22 33 44\nMifare Classic type: 1K\nBlock 0: 11 22 33 44 44 08 04 00 00 00 00 00 00 00 00 00\nBlock 1: ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ?? ??\n"), FormatFlipper)
+	assert.Nil(t, err)
+	assert.Equal(t, "Mifare Classic 1K", decoded[0].Product)
+	assert.Len(t, decoded[0].Pages, 1)
+
+	classic, err := Decode(make([]byte, 1024), FormatBin)
+	assert.Nil(t, err)
+	assert.Len(t, classic[0].Pages, 64)
+
+	_, err = Decode([]byte("04 A2 B3 9D\nC4 D5 E6\n"), FormatHex)
+	assert.EqualError(t, err, "Line 2: page size is 3 bytes, expected 4 bytes")
+}
@@ -0,0 +1,76 @@
+package dump
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+)
+
+// restoreOrder is an order of writing memory regions. Lock bytes are written after pages they lock,
+// configuration is written last as AUTH0 protects pages written after it
+var restoreOrder = map[Kind]int{
+	KindUid:    0,
+	KindUser:   1,
+	KindLock:   2,
+	KindConfig: 3,
+}
+
+// restoreRank returns position of the region in restore order. Capability container is written with user memory
+// before static lock bytes which can lock it, CFG0 with AUTH0 is written the last
+func restoreRank(r Region) int {
+	switch r.Name {
+	case regionCC:
+		return restoreOrder[KindUser]
+	case regionCfg0:
+		return len(restoreOrder)
+	}
+
+	return restoreOrder[r.Kind]
+}
+
+// RestorePages selects pages of NTAG/Ultralight dump to be written back to the tag. User memory pages are always
+// selected, UID, lock and config pages only when their kind is allowed. Password pages are always skipped
+// as they are read as zeros
+func RestorePages(d Dump, allow ...Kind) (write []Page, skipped []Page, err error) {
+	l := Analyze(d)
+	if l.Family != FamilyType2 {
+		return nil, nil, errors.New("Restore is supported for NTAG/Ultralight tags only")
+	}
+
+	allowed := map[Kind]bool{KindUser: true}
+	for _, k := range allow {
+		allowed[k] = true
+	}
+
+	for _, p := range d.Pages {
+		r, ok := l.Region(p.Number)
+		if !ok || !allowed[r.Kind] || r.Name == regionPwd || r.Name == regionPack {
+			skipped = append(skipped, p)
+			continue
+		}
+		write = append(write, p)
+	}
+
+	sort.SliceStable(write, func(i, j int) bool {
+		ri, _ := l.Region(write[i].Number)
+		rj, _ := l.Region(write[j].Number)
+		return restoreRank(ri) < restoreRank(rj)
+	})
+
+	return write, skipped, nil
+}
+
+// Verify compares written pages with the tag dump read back after writing and returns pages which differ
+func Verify(written []Page, d Dump) []PageDiff {
+	pages := make([]Page, len(written))
+	for i, p := range written {
+		pages[i].Number = p.Number
+		pages[i].Data, _ = d.Page(p.Number)
+	}
+
+	return diffLayout(Dump{Pages: written}, Dump{Pages: pages}, Analyze(d))
+}
+
+// WriteCommand returns NTAG/Ultralight WRITE command for the page
+func WriteCommand(p Page) []byte {
+	return append([]byte{0xA2, byte(p.Number)}, p.Data...)
+}
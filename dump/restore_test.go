@@ -0,0 +1,52 @@
+package dump
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRestorePages(t *testing.T) {
+	d := newDump("NTAG213", nil, 4, ntag213Memory())
+
+	write, skipped, err := RestorePages(d)
+	assert.Nil(t, err)
+	assert.Len(t, write, 36)
+	assert.Equal(t, 4, write[0].Number)
+	assert.Len(t, skipped, 9)
+
+	write, skipped, err = RestorePages(d, KindLock, KindConfig)
+	assert.Nil(t, err)
+	assert.Len(t, write, 36+5)
+	// capability container is written with user memory before static lock bytes
+	assert.Equal(t, []int{3, 4}, []int{write[0].Number, write[1].Number})
+	assert.Equal(t, []int{2, 40, 42, 41}, []int{write[37].Number, write[38].Number, write[39].Number, write[40].Number})
+	assert.Equal(t, []int{0, 1, 43, 44}, []int{skipped[0].Number, skipped[1].Number, skipped[2].Number, skipped[3].Number})
+
+	assert.Equal(t, []byte{0xA2, 0x04, 0x03, 0x0C, 0xD1, 0x01}, WriteCommand(write[1]))
+
+	// pages from AUTH0 are protected when CFG0 is written, so it is written after lock bytes and CFG1
+	memory := ntag213Memory()
+	memory[41*4+3] = 0x10
+	write, _, err = RestorePages(newDump("NTAG213", nil, 4, memory), KindLock, KindConfig)
+	assert.Nil(t, err)
+	tail := write[len(write)-4:]
+	assert.Equal(t, []int{2, 40, 42, 41}, []int{tail[0].Number, tail[1].Number, tail[2].Number, tail[3].Number})
+	assert.Equal(t, []byte{0xA2, 0x29, 0x04, 0x00, 0x00, 0x10}, WriteCommand(tail[3]))
+
+	_, _, err = RestorePages(newDump("", nil, 16, make([]byte, 1024)))
+	assert.EqualError(t, err, "Restore is supported for NTAG/Ultralight tags only")
+}
+
+func TestVerify(t *testing.T) {
+	d := newDump("NTAG213", nil, 4, ntag213Memory())
+	write, _, err := RestorePages(d, KindLock)
+	assert.Nil(t, err)
+
+	assert.Empty(t, Verify(write, d))
+
+	memory := ntag213Memory()
+	memory[40*4] = 0x00
+	assert.Equal(t, []PageDiff{
+		{Page: 40, Kind: KindLock, Region: "Dynamic lock bytes", A: []byte{0x01, 0x00, 0x00, 0xBD}, B: []byte{0x00, 0x00, 0x00, 0xBD}, Changed: []int{0}},
+	}, Verify(write, newDump("NTAG213", nil, 4, memory)))
+}
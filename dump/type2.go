@@ -0,0 +1,282 @@
+package dump
+
+import (
+	"fmt"
+	"strings"
+)
+
+// password pages which are never read back by the tag
+const (
+	regionPwd  = "PWD"
+	regionPack = "PACK"
+)
+
+// regions which are written in specific order on restore
+const (
+	regionCC   = "Capability container"
+	regionCfg0 = "CFG0"
+)
+
+// type2Model describes memory of NFC Forum type 2 tag. Negative page numbers mean the tag doesn't have such pages
+type type2Model struct {
+	names []string
+	pages int
+	// dynamic lock bytes page and number of pages locked by one bit
+	dynLock      int
+	dynLockGroup int
+	cfg          int
+	// data area size byte of the capability container
+	ccSize byte
+}
+
+var type2Models = []type2Model{
+	{names: []string{"NTAG210", "MF0UL11", "Ultralight EV1"}, pages: 20, dynLock: -1, cfg: 0x10, ccSize: 0x06},
+	{names: []string{"NTAG212", "MF0UL21", "Ultralight EV1"}, pages: 41, dynLock: 0x24, dynLockGroup: 2, cfg: 0x25, ccSize: 0x10},
+	{names: []string{"NTAG213"}, pages: 45, dynLock: 0x28, dynLockGroup: 2, cfg: 0x29, ccSize: 0x12},
+	{names: []string{"NTAG215"}, pages: 135, dynLock: 0x82, dynLockGroup: 16, cfg: 0x83, ccSize: 0x3E},
+	{names: []string{"NTAG216"}, pages: 231, dynLock: 0xE2, dynLockGroup: 16, cfg: 0xE3, ccSize: 0x6D},
+	{names: []string{"Ultralight"}, pages: 16, dynLock: -1, cfg: -1, ccSize: 0x06},
+}
+
+// findType2Model looks for the model by product name first, then by memory size and data area size of capability container
+func findType2Model(d Dump) (type2Model, bool) {
+	product := strings.ToLower(d.Product)
+	pages := lastPage(d) + 1
+	for _, m := range type2Models {
+		for _, name := range m.names {
+			if strings.Contains(product, strings.ToLower(name)) && (pages == m.pages || !strings.HasPrefix(name, "Ultralight")) {
+				return m, true
+			}
+		}
+	}
+
+	for _, m := range type2Models {
+		if pages == m.pages {
+			return m, true
+		}
+	}
+
+	if cc, ok := d.Page(3); ok && len(cc) == 4 && cc[0] == 0xE1 {
+		for _, m := range type2Models {
+			if cc[2] == m.ccSize && pages <= m.pages {
+				return m, true
+			}
+		}
+	}
+
+	return type2Model{}, false
+}
+
+// PackPage returns PACK page of NTAG210, NTAG212, NTAG213, NTAG215, NTAG216, MF0UL11 or MF0UL21 tag recognized
+// by product name. Ultralight EV1 names without the IC type aren't recognized as they don't tell the memory size
+func PackPage(product string) (int, bool) {
+	m, ok := findType2Model(Dump{Product: product})
+	if !ok || m.cfg < 0 {
+		return 0, false
+	}
+
+	return m.cfg + 3, true
+}
+
+func analyzeType2(d Dump) Layout {
+	l := Layout{Family: FamilyType2, Model: d.Product}
+	last := lastPage(d)
+
+	m, ok := findType2Model(d)
+	if ok {
+		if len(l.Model) == 0 {
+			l.Model = strings.Join(m.names, "/")
+		}
+		if last+1 < m.pages {
+			l.Warnings = append(l.Warnings, fmt.Sprintf("Dump is incomplete: %d of %d pages are read", last+1, m.pages))
+		}
+		last = m.pages - 1
+	} else {
+		m = type2Model{pages: last + 1, dynLock: -1, cfg: -1}
+		l.Warnings = append(l.Warnings, "Tag model is not recognized. Pages after capability container are shown as user memory")
+	}
+
+	var uid [7]byte
+	page0, _ := d.Page(0)
+	page1, _ := d.Page(1)
+	page2, _ := d.Page(2)
+	if len(page0) == 4 && len(page1) == 4 {
+		copy(uid[:3], page0[:3])
+		copy(uid[3:], page1)
+		l.add(0, 1, KindUid, "UID0-UID2, BCC0",
+			fmt.Sprintf("UID % X", uid),
+			bccNote("BCC0", page0[3], 0x88, uid[0], uid[1], uid[2]))
+	} else {
+		l.add(0, 1, KindUid, "UID0-UID2, BCC0")
+	}
+	l.add(1, 1, KindUid, "UID3-UID6")
+
+	if len(page2) == 4 {
+		l.add(2, 1, KindLock, "BCC1, internal, static lock bytes",
+			bccNote("BCC1", page2[0], uid[3:]...),
+			staticLockNote(page2[2], page2[3]),
+			staticBlockLockNote(page2[2]))
+	} else {
+		l.add(2, 1, KindLock, "BCC1, internal, static lock bytes")
+	}
+
+	page3, _ := d.Page(3)
+	l.add(3, 1, KindConfig, regionCC, ccNotes(page3)...)
+
+	userLast := last
+	if m.dynLock >= 0 {
+		userLast = m.dynLock - 1
+	} else if m.cfg >= 0 {
+		userLast = m.cfg - 1
+	}
+	if userLast >= 4 {
+		l.add(4, userLast-3, KindUser, "User memory", fmt.Sprintf("%d bytes, NDEF TLV area", (userLast-3)*4))
+		l.Data = readArea(d, 4, userLast)
+	}
+
+	if m.dynLock >= 0 {
+		data, _ := d.Page(m.dynLock)
+		l.add(m.dynLock, 1, KindLock, "Dynamic lock bytes", dynLockNotes(data, m.dynLockGroup, userLast)...)
+	}
+
+	if m.cfg >= 0 {
+		cfg0, _ := d.Page(m.cfg)
+		cfg1, _ := d.Page(m.cfg + 1)
+		l.add(m.cfg, 1, KindConfig, regionCfg0, cfg0Notes(cfg0, last)...)
+		l.add(m.cfg+1, 1, KindConfig, "CFG1", cfg1Notes(cfg1)...)
+		l.add(m.cfg+2, 1, KindConfig, regionPwd, "Password is always read as 00 00 00 00")
+		pack, _ := d.Page(m.cfg + 3)
+		if len(pack) == 4 {
+			l.add(m.cfg+3, 1, KindConfig, regionPack, fmt.Sprintf("Password acknowledge % X", pack[:2]))
+		} else {
+			l.add(m.cfg+3, 1, KindConfig, regionPack)
+		}
+	}
+
+	return l
+}
+
+func staticLockNote(lock0, lock1 byte) string {
+	var locked []int
+	if lock0&0x08 != 0 {
+		locked = append(locked, 3)
+	}
+	for i := uint(4); i < 8; i++ {
+		if lock0&(1<<i) != 0 {
+			locked = append(locked, int(i))
+		}
+	}
+	for i := uint(0); i < 8; i++ {
+		if lock1&(1<<i) != 0 {
+			locked = append(locked, int(i)+8)
+		}
+	}
+
+	return "Locked pages: " + FormatPages(locked)
+}
+
+func staticBlockLockNote(lock0 byte) string {
+	var blocked []string
+	for i, name := range []string{"CC", "pages 4-9", "pages 10-15"} {
+		if lock0&(1<<uint(i)) != 0 {
+			blocked = append(blocked, name)
+		}
+	}
+	if len(blocked) == 0 {
+		return "Block-locking bits: none"
+	}
+
+	return "Block-locking bits: " + strings.Join(blocked, ", ")
+}
+
+func ccNotes(cc []byte) []string {
+	if len(cc) != 4 {
+		return nil
+	}
+	if cc[0] != 0xE1 {
+		return []string{fmt.Sprintf("Magic number 0x%02X is not 0xE1. Tag is not NDEF formatted", cc[0])}
+	}
+
+	read := "granted"
+	if cc[3]>>4 != 0 {
+		read = fmt.Sprintf("0x%X", cc[3]>>4)
+	}
+	write := "granted"
+	switch cc[3] & 0x0F {
+	case 0x00:
+	case 0x0F:
+		write = "denied"
+	default:
+		write = fmt.Sprintf("0x%X", cc[3]&0x0F)
+	}
+
+	return []string{
+		fmt.Sprintf("NDEF mapping version %d.%d", cc[1]>>4, cc[1]&0x0F),
+		fmt.Sprintf("Data area size %d bytes", int(cc[2])*8),
+		fmt.Sprintf("Read access %s, write access %s", read, write),
+	}
+}
+
+func dynLockNotes(data []byte, group, userLast int) []string {
+	if len(data) != 4 {
+		return nil
+	}
+
+	var locked []int
+	for bit := 0; bit < 16; bit++ {
+		first := 16 + bit*group
+		if first > userLast {
+			break
+		}
+		if data[bit/8]&(1<<uint(bit%8)) == 0 {
+			continue
+		}
+		for p := first; p < first+group && p <= userLast; p++ {
+			locked = append(locked, p)
+		}
+	}
+
+	return []string{
+		"Locked pages: " + FormatPages(locked),
+		fmt.Sprintf("Block-locking bits 0x%02X", data[2]),
+	}
+}
+
+func cfg0Notes(data []byte, last int) []string {
+	if len(data) != 4 {
+		return nil
+	}
+
+	auth0 := "password protection is disabled"
+	if int(data[3]) <= last {
+		auth0 = fmt.Sprintf("password protection starts from page %d", data[3])
+	}
+
+	return []string{
+		fmt.Sprintf("MIRROR 0x%02X, MIRROR_PAGE 0x%02X", data[0], data[2]),
+		fmt.Sprintf("AUTH0 0x%02X: %s", data[3], auth0),
+	}
+}
+
+func cfg1Notes(data []byte) []string {
+	if len(data) != 4 {
+		return nil
+	}
+
+	access := data[0]
+	prot := "write access is protected"
+	if access&0x80 != 0 {
+		prot = "read and write access is protected"
+	}
+	notes := []string{fmt.Sprintf("ACCESS 0x%02X: %s", access, prot)}
+	if access&0x40 != 0 {
+		notes = append(notes, "Configuration is locked")
+	}
+	if access&0x07 != 0 {
+		notes = append(notes, fmt.Sprintf("AUTHLIM %d: negative password attempts are limited", access&0x07))
+	} else {
+		notes = append(notes, "AUTHLIM 0: negative password attempts are not limited")
+	}
+
+	return notes
+}
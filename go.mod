@@ -5,6 +5,7 @@ go 1.13
 require (
 	github.com/bmizerany/assert v0.0.0-20160611221934-b7ed37b82869 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/f2prateek/train v0.0.0-20170409194429-523ebcaf2f00
 	github.com/fatih/color v1.10.0
 	github.com/gohttp/response v1.2.0 // indirect
 	github.com/gorilla/websocket v1.4.1
@@ -14,4 +15,6 @@ require (
 	github.com/taglme/nfc-goclient v1.1.6
 	github.com/urfave/cli/v2 v2.1.1
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
+	gopkg.in/yaml.v2 v2.2.8
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,431 @@
+package jobfile
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/utils"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// includeKey is a step which inserts steps of the fragment file
+const includeKey = "include"
+
+var varPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Document is a YAML or JSON job file. Steps are named by commands with params, i.e.
+// "- write_ndef: {message: [...]}", or include steps of fragment file with "- include: lock.yaml"
+type Document struct {
+	Vars map[string]string `yaml:"vars"`
+	Jobs []Job             `yaml:"jobs"`
+}
+
+type Job struct {
+	Name   string        `yaml:"name"`
+	Repeat int           `yaml:"repeat"`
+	Expire int           `yaml:"expire"`
+	Steps  []interface{} `yaml:"steps"`
+}
+
+// fragment is a file with steps included by jobs. It is a list of steps or a document with steps list
+type fragment struct {
+	Steps []interface{} `yaml:"steps"`
+}
+
+// paramKeys are params of commands. Byte params are HEX strings
+var paramKeys = map[string][]string{
+	apiModels.CommandTransmitAdapter.String(): {"tx_bytes"},
+	apiModels.CommandTransmitTag.String():     {"tx_bytes"},
+	apiModels.CommandWriteNdef.String():       {"message"},
+	apiModels.CommandSetPassword.String():     {"password"},
+	apiModels.CommandAuthPassword.String():    {"password"},
+	apiModels.CommandSetLocale.String():       {"locale"},
+}
+
+var hexParams = map[string]bool{
+	"tx_bytes": true,
+	"password": true,
+}
+
+// DefaultExpire is expiration of jobs in seconds set by timeout flag by default
+const DefaultExpire = 60
+
+// Entry is a job of the file with the line where it starts
+type Entry struct {
+	Job  apiModels.NewJob
+	Line int
+}
+
+// Resolve returns the job with expiration and name set by command flags. Expiration of the file is kept
+// when the flag has default value
+func (e Entry) Resolve(expire int, name string) apiModels.NewJob {
+	nj := e.Job
+	if expire != DefaultExpire || nj.ExpireAfter == 0 {
+		nj.ExpireAfter = expire
+	}
+	if len(name) > 0 {
+		nj.JobName = name
+	}
+
+	return nj
+}
+
+// Problem is a problem of the job file. Line is zero when it can't be located
+type Problem struct {
+	Line    int
+	Message string
+	// job and step locate the problem in the document, step is -1 for problems of the job
+	job  int
+	step int
+}
+
+// Error lists all problems of the job file
+type Error struct {
+	Filename string
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = e.Filename
+		if p.Line > 0 {
+			lines[i] += fmt.Sprintf(":%d", p.Line)
+		}
+		lines[i] += ": " + p.Message
+	}
+
+	return "Job file is not valid:\n" + strings.Join(lines, "\n")
+}
+
+// IsJobFile reports whether data is a job document rather than JSON lines of new job resources
+func IsJobFile(data []byte) bool {
+	var doc map[string]interface{}
+	if yaml.Unmarshal(data, &doc) != nil {
+		return false
+	}
+	_, ok := doc["jobs"]
+
+	return ok
+}
+
+// Load reads job document or JSON lines of new job resources. Variables of job document are taken from vars,
+// environment and vars section of the file in this order. Params of all steps are checked and problems
+// of all jobs are reported together with file lines
+func Load(filename string, vars map[string]string) ([]Entry, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read job file")
+	}
+
+	if IsJobFile(data) {
+		return Parse(data, filename, vars)
+	}
+
+	return parseLines(data, filename)
+}
+
+// Parse compiles job document to new jobs. Includes are resolved relative to the file directory
+func Parse(data []byte, filename string, vars map[string]string) ([]Entry, error) {
+	var doc Document
+	err := yaml.UnmarshalStrict(data, &doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse job file")
+	}
+	if len(doc.Jobs) == 0 {
+		return nil, errors.New("Job file doesn't contain any jobs")
+	}
+
+	c := compiler{dir: filepath.Dir(filename), vars: vars, fileVars: doc.Vars}
+	l := utils.NewSourceLines(data)
+	entries := make([]Entry, len(doc.Jobs))
+	for i, j := range doc.Jobs {
+		entries[i] = Entry{Job: c.job(i, j), Line: l.Line("jobs", i)}
+	}
+
+	if len(c.problems) > 0 {
+		for i, p := range c.problems {
+			if p.step < 0 {
+				c.problems[i].Line = l.Line("jobs", p.job)
+			} else {
+				c.problems[i].Line = l.Line("jobs", p.job, "steps", p.step)
+			}
+		}
+		return nil, &Error{Filename: filename, Problems: c.problems}
+	}
+
+	return entries, nil
+}
+
+type compiler struct {
+	dir      string
+	vars     map[string]string
+	fileVars map[string]string
+	problems []Problem
+	// prefix, jobIndex and stepIndex locate problems of the current job and step
+	prefix    string
+	jobIndex  int
+	stepIndex int
+}
+
+func (c *compiler) problem(format string, a ...interface{}) {
+	c.problems = append(c.problems, Problem{Message: c.prefix + fmt.Sprintf(format, a...), job: c.jobIndex, step: c.stepIndex})
+}
+
+func (c *compiler) job(i int, j Job) apiModels.NewJob {
+	c.jobIndex, c.stepIndex = i, -1
+	c.prefix = fmt.Sprintf("job %d: ", i+1)
+	name := c.substitute(j.Name)
+	if len(name) == 0 {
+		c.problem("Name is required")
+	} else {
+		c.prefix = fmt.Sprintf("job %d (%s): ", i+1, name)
+	}
+
+	repeat := j.Repeat
+	if repeat == 0 {
+		repeat = 1
+	}
+	if repeat < 0 {
+		c.problem("Repeat can't be negative")
+	}
+	if j.Expire < 0 {
+		c.problem("Expire can't be negative")
+	}
+	if len(j.Steps) == 0 {
+		c.problem("Steps are required")
+	}
+
+	nj := apiModels.NewJob{
+		JobName:     name,
+		Repeat:      repeat,
+		ExpireAfter: j.Expire,
+	}
+	nj.Steps = c.steps(c.prefix, j.Steps, c.dir, nil)
+
+	return nj
+}
+
+// steps compiles steps list. Included files are tracked to report include cycles
+func (c *compiler) steps(prefix string, steps []interface{}, dir string, included []string) []apiModels.JobStepResource {
+	var res []apiModels.JobStepResource
+	for i, item := range steps {
+		// steps of included files are located by the include step
+		if len(included) == 0 {
+			c.stepIndex = i
+		}
+		c.prefix = fmt.Sprintf("%sstep %d: ", prefix, i+1)
+		command, params, ok := c.splitStep(item)
+		if !ok {
+			continue
+		}
+
+		if command == includeKey {
+			name, isString := params.(string)
+			if !isString {
+				c.problem("Include should be a file name")
+				continue
+			}
+			res = append(res, c.include(c.prefix, filepath.Join(dir, c.substitute(name)), included)...)
+			continue
+		}
+
+		step, ok := c.step(command, params)
+		if ok {
+			res = append(res, step)
+		}
+	}
+
+	return res
+}
+
+func (c *compiler) include(prefix, filename string, included []string) []apiModels.JobStepResource {
+	for _, f := range included {
+		if f == filename {
+			c.problem("Include cycle of %s", filename)
+			return nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		c.problem("Can't read included file: %s", err)
+		return nil
+	}
+
+	var f fragment
+	if yaml.UnmarshalStrict(data, &f.Steps) != nil {
+		err = yaml.UnmarshalStrict(data, &f)
+		if err != nil {
+			c.problem("Can't parse included file %s: %s", filename, err)
+			return nil
+		}
+	}
+
+	return c.steps(prefix+filepath.Base(filename)+" ", f.Steps, filepath.Dir(filename), append(included, filename))
+}
+
+// splitStep returns command name and params of the step written as command name or map with the only command key
+func (c *compiler) splitStep(item interface{}) (string, interface{}, bool) {
+	switch s := item.(type) {
+	case string:
+		return s, nil, true
+	case map[interface{}]interface{}:
+		if len(s) == 1 {
+			for k, v := range s {
+				if command, ok := k.(string); ok {
+					return command, v, true
+				}
+			}
+		}
+	}
+	c.problem("Step should be a command name or a map with command name key and its params")
+
+	return "", nil, false
+}
+
+func (c *compiler) step(command string, params interface{}) (apiModels.JobStepResource, bool) {
+	var step apiModels.JobStepResource
+	if _, ok := apiModels.StringToCommand(command); !ok {
+		c.problem("Unknown command %s. Can be one of: %s", command, strings.Join(commandNames(), ", "))
+		return step, false
+	}
+
+	values, ok := c.params(command, params)
+	if !ok {
+		return step, false
+	}
+
+	encoded, err := json.Marshal(map[string]interface{}{"command": command, "params": values})
+	if err != nil {
+		c.problem("Can't encode params: %s", err)
+		return step, false
+	}
+	err = json.Unmarshal(encoded, &step)
+	if err == nil {
+		_, err = step.ToJobStep()
+	}
+	if err != nil {
+		c.problem("%s", err)
+		return step, false
+	}
+
+	return step, true
+}
+
+// params converts params of the step to params resource with substituted variables and base64 encoded bytes
+func (c *compiler) params(command string, params interface{}) (map[string]interface{}, bool) {
+	values := map[string]interface{}{}
+	if params == nil {
+		return values, true
+	}
+
+	m, ok := params.(map[interface{}]interface{})
+	if !ok {
+		c.problem("Params of %s should be a map", command)
+		return nil, false
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, fmt.Sprint(k))
+	}
+	sort.Strings(keys)
+
+	valid := true
+	for _, key := range keys {
+		v := m[key]
+		if !containsKey(paramKeys[command], key) {
+			c.problem("Unknown param %s of %s", key, command)
+			valid = false
+			continue
+		}
+
+		problems := len(c.problems)
+		v = c.resolve(v)
+		if len(c.problems) > problems {
+			valid = false
+			continue
+		}
+		if hexParams[key] {
+			b, err := utils.ParseHexString(fmt.Sprint(v))
+			if err != nil {
+				c.problem("Param %s should be HEX string, i.e. \"03 AD F3 41\"", key)
+				valid = false
+				continue
+			}
+			v = base64.StdEncoding.EncodeToString(b)
+		}
+		values[key] = v
+	}
+
+	return values, valid
+}
+
+// resolve substitutes variables in strings and converts maps to be encoded with JSON
+func (c *compiler) resolve(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return c.substitute(val)
+	case map[interface{}]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			res[fmt.Sprint(k)] = c.resolve(item)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, item := range val {
+			res[i] = c.resolve(item)
+		}
+		return res
+	}
+
+	return v
+}
+
+func (c *compiler) substitute(s string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := varPattern.FindStringSubmatch(m)[1]
+		if v, ok := c.vars[name]; ok {
+			return v
+		}
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+		if v, ok := c.fileVars[name]; ok {
+			return v
+		}
+		c.problem("Variable %s is not set", name)
+
+		return m
+	})
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func commandNames() []string {
+	var names []string
+	for c := apiModels.CommandGetTags; c <= apiModels.CommandSetLocale; c++ {
+		names = append(names, c.String())
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,128 @@
+package jobfile
+
+import (
+	"github.com/stretchr/testify/assert"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testJobFile = `
+vars:
+  URL: https://tagl.me
+jobs:
+  - name: Write ${URL}
+    repeat: 3
+    expire: 30
+    steps:
+      - include: auth.yaml
+      - write_ndef:
+          message:
+            - type: url
+              data:
+                url: ${URL}
+      - read_ndef
+`
+
+func TestRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfc-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "auth.yaml"), []byte("- auth_password:\n    password: ${TAG_PASSWORD}\n"), 0644)
+	assert.Nil(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "jobs.yaml"), []byte(testJobFile), 0644)
+	assert.Nil(t, err)
+
+	assert.True(t, IsJobFile([]byte(testJobFile)))
+	assert.False(t, IsJobFile([]byte(`{"job_name":"Read tag","repeat":1,"expire_after":60,"steps":[]}`)))
+
+	filename := filepath.Join(dir, "jobs.yaml")
+	_, err = Load(filename, nil)
+	assert.EqualError(t, err, "Job file is not valid:\n"+filename+":9: job 1 (Write https://tagl.me): step 1: auth.yaml step 1: Variable TAG_PASSWORD is not set")
+
+	entries, err := Load(filename, map[string]string{"TAG_PASSWORD": "01 02 03 04", "URL": "https://nfc.tagl.me"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, 5, entries[0].Line)
+	jobs := []apiModels.NewJob{entries[0].Job}
+	assert.Equal(t, "Write https://nfc.tagl.me", jobs[0].JobName)
+	assert.Equal(t, 3, jobs[0].Repeat)
+	assert.Equal(t, 30, jobs[0].ExpireAfter)
+	assert.Equal(t, 3, len(jobs[0].Steps))
+	assert.Equal(t, apiModels.AuthPasswordParamsResource{Password: "AQIDBA=="}, jobs[0].Steps[0].Params)
+	assert.Equal(t, "write_ndef", jobs[0].Steps[1].Command)
+	params, err := jobs[0].Steps[1].Params.ToParams()
+	assert.Nil(t, err)
+	assert.Equal(t, "Record 1: https://nfc.tagl.me (url)", params.String())
+	assert.Equal(t, "read_ndef", jobs[0].Steps[2].Command)
+}
+
+func TestParse_problems(t *testing.T) {
+	_, err := Parse([]byte(`
+jobs:
+  - steps:
+      - read_nfc
+      - transmit_tag:
+          tx_bytes: 30 0G
+      - get_dump: {page: 4}
+      - write_ndef:
+          message:
+            - type: url
+              data: {}
+  - name: Lock
+    repeat: -1
+    steps:
+      - include: lock.yaml
+`), "testdata/jobs.yaml", nil)
+	assert.EqualError(t, err, "Job file is not valid:\n"+
+		"testdata/jobs.yaml:3: job 1: Name is required\n"+
+		"testdata/jobs.yaml:4: job 1: step 1: Unknown command read_nfc. Can be one of: auth_password, format_default, get_dump, get_tags, "+
+		"lock_permanent, read_ndef, remove_password, set_locale, set_password, transmit_adapter, transmit_tag, write_ndef\n"+
+		"testdata/jobs.yaml:5: job 1: step 2: Param tx_bytes should be HEX string, i.e. \"03 AD F3 41\"\n"+
+		"testdata/jobs.yaml:7: job 1: step 3: Unknown param page of get_dump\n"+
+		"testdata/jobs.yaml:8: job 1: step 4: Url field of Url type record should be not empty\n"+
+		"testdata/jobs.yaml:12: job 2 (Lock): Repeat can't be negative\n"+
+		"testdata/jobs.yaml:15: job 2 (Lock): step 1: Can't read included file: open testdata/lock.yaml: no such file or directory")
+
+	_, err = Parse([]byte("jobs: []\n"), "", nil)
+	assert.EqualError(t, err, "Job file doesn't contain any jobs")
+}
+
+func TestParse_json(t *testing.T) {
+	_, err := Parse([]byte(`{
+  "jobs": [
+    {"name": "Read", "steps": ["read_ndef"]},
+    {
+      "name": "Transmit",
+      "steps": [
+        "get_tags",
+        {"transmit_tag": {"tx_bytes": "3G"}}
+      ]
+    }
+  ]
+}`), "jobs.json", nil)
+	assert.EqualError(t, err, "Job file is not valid:\n"+
+		"jobs.json:8: job 2 (Transmit): step 2: Param tx_bytes should be HEX string, i.e. \"03 AD F3 41\"")
+}
+
+func TestLoad_lines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfc-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "jobs.json")
+	err = ioutil.WriteFile(filename, []byte(`{"job_name":"Read tag","repeat":1,"expire_after":60,"steps":[{"command":"read_ndef","params":{}}]}
+
+{"job_name":"Transmit","repeat":1,"expire_after":60,"steps":[{"command":"transmit_tag","params":{"tx_bytes":"!!"}}]}
+{"job_name":"Write","steps":[{"command":"write_nfc","params":{}}]}
+`), 0644)
+	assert.Nil(t, err)
+
+	_, err = Load(filename, nil)
+	assert.EqualError(t, err, "Job file is not valid:\n"+
+		filename+":3: job Transmit: step 1: Could not decode tx_bytes. It should be base64 encoded\n"+
+		filename+":4: Can't parse the job: Job step have not valid command name")
+}
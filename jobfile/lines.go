@@ -0,0 +1,43 @@
+package jobfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"strings"
+)
+
+// parseLines parses JSON lines of new job resources. Params of all steps are checked
+func parseLines(data []byte, filename string) ([]Entry, error) {
+	var entries []Entry
+	var problems []Problem
+	for i, line := range strings.Split(string(data), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		nj := apiModels.NewJob{}
+		err := json.Unmarshal([]byte(line), &nj)
+		if err != nil {
+			problems = append(problems, Problem{Line: i + 1, Message: "Can't parse the job: " + err.Error()})
+			continue
+		}
+		for j, step := range nj.Steps {
+			_, err = step.ToJobStep()
+			if err != nil {
+				problems = append(problems, Problem{Line: i + 1, Message: fmt.Sprintf("job %s: step %d: %s", nj.JobName, j+1, err)})
+			}
+		}
+		entries = append(entries, Entry{Job: nj, Line: i + 1})
+	}
+
+	if len(problems) > 0 {
+		return nil, &Error{Filename: filename, Problems: problems}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("Job file doesn't contain any jobs")
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,71 @@
+package kdf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+// cmac computes AES-CMAC of the message as defined by RFC 4493
+func cmac(block cipher.Block, message []byte) []byte {
+	size := block.BlockSize()
+	k1 := make([]byte, size)
+	block.Encrypt(k1, k1)
+	k1 = shiftSubkey(k1)
+	k2 := shiftSubkey(k1)
+
+	n := (len(message) + size - 1) / size
+	complete := n > 0 && len(message)%size == 0
+	if n == 0 {
+		n = 1
+	}
+
+	last := make([]byte, size)
+	copy(last, message[(n-1)*size:])
+	if complete {
+		xor(last, k1)
+	} else {
+		last[len(message)-(n-1)*size] = 0x80
+		xor(last, k2)
+	}
+
+	mac := make([]byte, size)
+	for i := 0; i < n-1; i++ {
+		xor(mac, message[i*size:(i+1)*size])
+		block.Encrypt(mac, mac)
+	}
+	xor(mac, last)
+	block.Encrypt(mac, mac)
+
+	return mac
+}
+
+// shiftSubkey returns key shifted left by one bit and xored with Rb constant when the most significant bit is set
+func shiftSubkey(key []byte) []byte {
+	res := make([]byte, len(key))
+	for i := 0; i < len(key)-1; i++ {
+		res[i] = key[i]<<1 | key[i+1]>>7
+	}
+	res[len(key)-1] = key[len(key)-1] << 1
+	if key[0]&0x80 != 0 {
+		res[len(key)-1] ^= 0x87
+	}
+
+	return res
+}
+
+func xor(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func newAesCmac(key []byte) (func([]byte) []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(message []byte) []byte {
+		return cmac(block, message)
+	}, nil
+}
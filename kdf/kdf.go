@@ -0,0 +1,51 @@
+package kdf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+type Algorithm = string
+
+const (
+	AlgorithmHmacSha256 Algorithm = "hmac-sha256"
+	AlgorithmAesCmac    Algorithm = "aes-cmac"
+)
+
+var Algorithms = []Algorithm{AlgorithmHmacSha256, AlgorithmAesCmac}
+
+// Password is NTAG21x password and password acknowledge returned by the tag on successful authentication
+type Password struct {
+	Pwd  []byte
+	Pack []byte
+}
+
+// Derive computes MAC of the tag UID with the master key. First 4 bytes of MAC are the password and next 2 bytes are PACK
+func Derive(alg Algorithm, key, uid []byte) (Password, error) {
+	if len(key) == 0 {
+		return Password{}, errors.New("Master key is empty")
+	}
+	if len(uid) == 0 {
+		return Password{}, errors.New("Tag UID is empty")
+	}
+
+	var mac []byte
+	switch alg {
+	case AlgorithmHmacSha256:
+		h := hmac.New(sha256.New, key)
+		h.Write(uid)
+		mac = h.Sum(nil)
+	case AlgorithmAesCmac:
+		sum, err := newAesCmac(key)
+		if err != nil {
+			return Password{}, errors.Wrap(err, "AES-CMAC master key should be 16, 24 or 32 bytes long")
+		}
+		mac = sum(uid)
+	default:
+		return Password{}, errors.New(fmt.Sprintf("Unknown key derivation algorithm %s. Can be one of: %s, %s", alg, AlgorithmHmacSha256, AlgorithmAesCmac))
+	}
+
+	return Password{Pwd: mac[:4], Pack: mac[4:6]}, nil
+}
@@ -0,0 +1,60 @@
+package kdf
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// test vectors of RFC 4493
+func TestCmac(t *testing.T) {
+	block, err := aes.NewCipher(mustHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	assert.Nil(t, err)
+
+	message := mustHex("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	for _, c := range []struct {
+		size int
+		mac  string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	} {
+		assert.Equal(t, c.mac, hex.EncodeToString(cmac(block, message[:c.size])))
+	}
+}
+
+func TestDerive(t *testing.T) {
+	uid := mustHex("04A23B12C45E80")
+
+	p, err := Derive(AlgorithmHmacSha256, []byte("master key"), uid)
+	assert.Nil(t, err)
+	assert.Len(t, p.Pwd, 4)
+	assert.Len(t, p.Pack, 2)
+
+	other, err := Derive(AlgorithmHmacSha256, []byte("master key"), mustHex("04A23B12C45E81"))
+	assert.Nil(t, err)
+	assert.NotEqual(t, p.Pwd, other.Pwd)
+
+	p, err = Derive(AlgorithmAesCmac, mustHex("2b7e151628aed2a6abf7158809cf4f3c"), uid)
+	assert.Nil(t, err)
+	block, _ := aes.NewCipher(mustHex("2b7e151628aed2a6abf7158809cf4f3c"))
+	mac := cmac(block, uid)
+	assert.Equal(t, Password{Pwd: mac[:4], Pack: mac[4:6]}, p)
+
+	_, err = Derive(AlgorithmAesCmac, []byte("short"), uid)
+	assert.EqualError(t, err, "AES-CMAC master key should be 16, 24 or 32 bytes long: crypto/aes: invalid key size 5")
+
+	_, err = Derive("md5", []byte("key"), uid)
+	assert.EqualError(t, err, "Unknown key derivation algorithm md5. Can be one of: hmac-sha256, aes-cmac")
+}
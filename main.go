@@ -2,6 +2,9 @@ package main
 
 import (
 	"log"
+	"os"
+
+	"github.com/f2prateek/train"
 
 	"github.com/taglme/nfc-cli/opts"
 	"github.com/taglme/nfc-cli/repository"
@@ -24,18 +27,19 @@ func main() {
 	var app service.AppService
 
 	cbCliStarted := func(url string) {
+		var interceptors []train.Interceptor
 		if AppID != "" && AppSecret != "" && AppCert != "" {
 			privateRSAKey, err := client.PrivateRSAKeyFromB64String(AppSecret)
 			if err != nil {
 				log.Fatal(err)
 			}
 			auth := client.NewSigner(AppID, privateRSAKey, AppCert)
-			nfc = client.New(url, auth)
-		} else {
-			nfc = client.New(url)
+			interceptors = append(interceptors, auth)
 		}
+		nfc = client.New(url, interceptors...)
 
 		rep = repository.New(&nfc)
+		rep.SetHost(url, interceptors...)
 		app.SetRepository(rep)
 	}
 
@@ -51,6 +55,7 @@ func main() {
 	err := app.Start()
 
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
+		os.Exit(service.ExitCode(err))
 	}
 }
@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"errors"
 	"github.com/taglme/nfc-cli/models"
 	"github.com/taglme/nfc-cli/ndef"
 	"github.com/taglme/nfc-goclient/pkg/client"
@@ -13,23 +14,94 @@ func NewRepositoryService(c **client.Client) *MockedRepositoryService {
 	return &MockedRepositoryService{}
 }
 
-func (s *MockedRepositoryService) GetVersion() (apiModels.AppInfo, error) {
+func (s *MockedRepositoryService) GetVersion(withOutput bool) (apiModels.AppInfo, error) {
 	return apiModels.AppInfo{}, nil
 }
 
-func (s *MockedRepositoryService) GetAdapters(withOutput bool) ([]apiModels.Adapter, error) {
-	return []apiModels.Adapter{
+func (s *MockedRepositoryService) GetAdapters(adapterType *apiModels.AdapterType, withOutput bool) ([]apiModels.Adapter, error) {
+	adapters := []apiModels.Adapter{
 		{
 			AdapterID: "mocked adapter id",
 			Name:      "Mocker adapter name",
+			Type:      apiModels.AdapterTypeNfc,
+		},
+	}
+	if adapterType != nil && *adapterType != apiModels.AdapterTypeNfc {
+		return nil, nil
+	}
+
+	return adapters, nil
+}
+
+func (s *MockedRepositoryService) GetTags(adapterId string, tagType *apiModels.TagType, withOutput bool) ([]apiModels.Tag, error) {
+	return []apiModels.Tag{
+		{
+			TagID:     "mocked tag id",
+			Type:      apiModels.TagTypeNfc,
+			AdapterID: adapterId,
+			Uid:       []byte{0x04, 0xa2, 0x3b, 0x12},
 		},
 	}, nil
 }
 
-func (s *MockedRepositoryService) GetJob(adapterId, id string) (apiModels.Job, error) {
+func (s *MockedRepositoryService) GetTag(adapterId, tagId string, withOutput bool) (apiModels.Tag, error) {
+	return apiModels.Tag{
+		TagID:     tagId,
+		Type:      apiModels.TagTypeNfc,
+		AdapterID: adapterId,
+		Uid:       []byte{0x04, 0xa2, 0x3b, 0x12},
+	}, nil
+}
+
+func (s *MockedRepositoryService) GetJobs(adapterId string, filter client.JobFilter, withOutput bool) ([]apiModels.Job, apiModels.PageInfo, error) {
+	return []apiModels.Job{
+		{
+			JobID:     "mocked job id",
+			JobName:   "Mocked job name",
+			AdapterID: adapterId,
+			Status:    apiModels.JobStatusActive,
+			Repeat:    1,
+		},
+	}, apiModels.PageInfo{Total: 1, Length: 1}, nil
+}
+
+func (s *MockedRepositoryService) GetJob(adapterId, id string, withOutput bool) (apiModels.Job, error) {
 	return apiModels.Job{}, nil
 }
 
+func (s *MockedRepositoryService) DeleteJob(adapterId, id string) error {
+	return nil
+}
+
+func (s *MockedRepositoryService) UpdateJobStatus(adapterId, id string, status apiModels.JobStatus, withOutput bool) (apiModels.Job, error) {
+	return apiModels.Job{JobID: id, AdapterID: adapterId, Status: status}, nil
+}
+
+func (s *MockedRepositoryService) GetRuns(adapterId string, filter client.RunFilter, withOutput bool) ([]apiModels.JobRun, apiModels.PageInfo, error) {
+	return []apiModels.JobRun{
+		{
+			RunID:     "mocked run id",
+			JobID:     "mocked job id",
+			JobName:   "Mocked job name",
+			AdapterID: adapterId,
+			Status:    apiModels.JobRunStatusSuccess,
+		},
+	}, apiModels.PageInfo{Total: 1, Length: 1}, nil
+}
+
+func (s *MockedRepositoryService) GetRun(adapterId, id string, withOutput bool) (apiModels.JobRun, error) {
+	return apiModels.JobRun{RunID: id, AdapterID: adapterId, Status: apiModels.JobRunStatusSuccess}, nil
+}
+
+func (s *MockedRepositoryService) GetEvents(adapterId *string, filter client.EventFilter, withOutput bool) ([]apiModels.Event, apiModels.PageInfo, error) {
+	return []apiModels.Event{
+		{
+			EventID: "mocked event id",
+			Name:    apiModels.EventNameTagDiscovery,
+		},
+	}, apiModels.PageInfo{Total: 1, Length: 1}, nil
+}
+
 func (s *MockedRepositoryService) DeleteAdapterJobs(adapterId string) error {
 	return nil
 }
@@ -53,7 +125,7 @@ func (s *MockedRepositoryService) AddGenericJob(p models.GenericJobParams) (*api
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
-func (s *MockedRepositoryService) AddSetPwdJob(p models.GenericJobParams, password []byte) (*apiModels.Job, *apiModels.NewJob, error) {
+func (s *MockedRepositoryService) AddSetPwdJob(p models.GenericJobParams, password []byte, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error) {
 	nj := apiModels.NewJob{
 		JobName:     "Job Name",
 		Repeat:      p.Repeat,
@@ -75,7 +147,7 @@ func (s *MockedRepositoryService) AddTransmitJob(p models.GenericJobParams, txBy
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
-func (s *MockedRepositoryService) AddWriteJob(p models.GenericJobParams, r ndef.NdefPayload, protect bool) (*apiModels.Job, *apiModels.NewJob, error) {
+func (s *MockedRepositoryService) AddRestoreJob(p models.GenericJobParams, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error) {
 	nj := apiModels.NewJob{
 		JobName:     "Job Name",
 		Repeat:      p.Repeat,
@@ -86,10 +158,31 @@ func (s *MockedRepositoryService) AddWriteJob(p models.GenericJobParams, r ndef.
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
-func (s *MockedRepositoryService) RunWsConnection(handler func(models.Event, interface{}), errHandler func(error)) error {
+func (s *MockedRepositoryService) AddWriteJob(p models.GenericJobParams, records []ndef.NdefPayload, protect, verify bool) (*apiModels.Job, *apiModels.NewJob, error) {
+	nj := apiModels.NewJob{
+		JobName:     "Job Name",
+		Repeat:      p.Repeat,
+		ExpireAfter: p.Expire,
+		Steps:       []apiModels.JobStepResource{},
+	}
+
+	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
+}
+
+func (s *MockedRepositoryService) RunWsConnection(handler func(models.Event, interface{}), errHandler func(error), reconnectHandler func()) error {
+	return nil
+}
+
+func (s *MockedRepositoryService) FollowEvents(adapterId *string, name *apiModels.EventName, withOutput bool, eHandler func(apiModels.Event), errHandler func(error)) error {
+	go func() {
+		eHandler(apiModels.Event{EventID: "mocked event id", Name: apiModels.EventNameAdapterDiscovery})
+		errHandler(errors.New("mocked connection closed"))
+	}()
 	return nil
 }
 
 func (s *MockedRepositoryService) StopWsConnection() error {
 	return nil
 }
+
+func (s *MockedRepositoryService) SetAdapterPrefix(enabled bool) {}
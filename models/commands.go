@@ -14,4 +14,25 @@ const (
 	CommandRmpwd    Command = "rmpwd"
 	CommandFormat   Command = "format"
 	CommandRun      Command = "run"
+	CommandTags     Command = "tags"
+	CommandJobs     Command = "jobs"
+	CommandRuns     Command = "runs"
+	CommandEvents   Command = "events"
+	CommandNdef     Command = "ndef"
+	CommandRestore  Command = "restore"
+	CommandConfig   Command = "config"
+
+	CommandList    Command = "ls"
+	CommandShow    Command = "show"
+	CommandRemove  Command = "rm"
+	CommandPause   Command = "pause"
+	CommandResume  Command = "resume"
+	CommandEncode  Command = "encode"
+	CommandDecode  Command = "decode"
+	CommandAnalyze Command = "analyze"
+	CommandExport  Command = "export"
+	CommandDiff    Command = "diff"
+	CommandGet     Command = "get"
+	CommandSet     Command = "set"
+	CommandLint    Command = "lint"
 )
@@ -0,0 +1,14 @@
+package models
+
+type ExitCode = int
+
+// Exit codes of the process. Other errors exit with 1
+const (
+	ExitSuccess     ExitCode = 0
+	ExitError       ExitCode = 1
+	ExitRunsFailed  ExitCode = 2
+	ExitTimeout     ExitCode = 3
+	ExitUnreachable ExitCode = 4
+	ExitValidation  ExitCode = 5
+	ExitInterrupted ExitCode = 130
+)
@@ -3,24 +3,69 @@ package models
 type Flag = string
 
 const (
-	FlagHost    Flag = "host"
-	FlagAdapter Flag = "adapter"
-	FlagRepeat  Flag = "repeat"
-	FlagOutput  Flag = "output"
-	FlagAppend  Flag = "append"
-	FlagTimeout Flag = "timeout"
-	FlagFile    Flag = "file"
-	FlagAuth    Flag = "auth"
-	FlagJobName Flag = "name"
-	FlagExport  Flag = "export"
+	FlagHost     Flag = "host"
+	FlagAdapter  Flag = "adapter"
+	FlagRepeat   Flag = "repeat"
+	FlagOutput   Flag = "output"
+	FlagAppend   Flag = "append"
+	FlagTimeout  Flag = "timeout"
+	FlagDeadline Flag = "deadline"
+	FlagIdle     Flag = "idle"
+	FlagFile     Flag = "file"
+	FlagAuth     Flag = "auth"
+	FlagJobName  Flag = "name"
+	FlagExport   Flag = "export"
+	FlagFormat   Flag = "format"
+
+	FlagAuthDerive Flag = "auth-derive"
+	FlagDerive     Flag = "derive"
+	FlagKdf        Flag = "kdf"
+	FlagKeyFile    Flag = "key-file"
+
+	FlagProfile Flag = "profile"
+	FlagConfig  Flag = "config"
+
+	FlagAllAdapters Flag = "all-adapters"
+
+	FlagVar      Flag = "var"
+	FlagValidate Flag = "validate"
+	FlagDryRun   Flag = "dry-run"
+
+	FlagStatus  Flag = "status"
+	FlagSortBy  Flag = "sort"
+	FlagSortDir Flag = "sort-dir"
+	FlagLimit   Flag = "limit"
+	FlagOffset  Flag = "offset"
+	FlagJobId   Flag = "job-id"
+	FlagEvent   Flag = "event"
+	FlagFollow  Flag = "follow"
 
 	FlagPwd Flag = "password"
 
+	// FlagTagType is a tag type filter of tags command named as NDEF type flag
+	FlagTagType Flag = "type"
+
 	FlagTarget  Flag = "target"
 	FlagTxBytes Flag = "tx-bytes"
 
-	FlagNdefType Flag = "ndef-type"
-	FlagProtect  Flag = "protect"
+	FlagAnalyze    Flag = "analyze"
+	FlagDumpFormat Flag = "dump-format"
+	FlagDiff       Flag = "diff"
+	FlagFrom       Flag = "from"
+	FlagAllow      Flag = "allow"
+
+	FlagNdefType     Flag = "ndef-type"
+	FlagProtect      Flag = "protect"
+	FlagVerify       Flag = "verify"
+	FlagBatch        Flag = "batch"
+	FlagBatchResults Flag = "batch-results"
+	FlagResume       Flag = "resume"
+	FlagSigKey       Flag = "sig-key"
+	FlagCounterStart Flag = "counter-start"
+	FlagRecord       Flag = "record"
+	FlagMessageFile  Flag = "message-file"
+	FlagEncoding     Flag = "encoding"
+	FlagTlv          Flag = "tlv"
 
 	FlagNdefTypeRawId      Flag = "id"
 	FlagNdefTypeRawTnf     Flag = "tnf"
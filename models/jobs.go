@@ -8,6 +8,8 @@ type GenericJobParams struct {
 	Auth      []byte
 	Export    bool
 	JobName   string
+	// Vars are substituted in job files
+	Vars map[string]string
 }
 
 type NewJob struct {
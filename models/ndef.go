@@ -30,6 +30,34 @@ var NdefTypeValues = []NdefType{
 	NdefTypePoster,
 }
 
+var NdefTypeFields = map[NdefType][]Flag{
+	NdefTypeRaw:  {FlagNdefTypeRawTnf, FlagNdefTypeType, FlagNdefTypeRawId, FlagNdefTypeRawPayload},
+	NdefTypeUrl:  {FlagNdefTypeUrl},
+	NdefTypeText: {FlagNdefTypeText, FlagNdefTypeLang},
+	NdefTypeUri:  {FlagNdefUri},
+	NdefTypeVcard: {
+		FlagNdefTypeVcardAddressCity,
+		FlagNdefTypeVcardAddressCountry,
+		FlagNdefTypeVcardAddressPostalCode,
+		FlagNdefTypeVcardAddressRegion,
+		FlagNdefTypeVcardAddressStreet,
+		FlagNdefTypeVcardEmail,
+		FlagNdefTypeVcardFirstName,
+		FlagNdefTypeVcardLastName,
+		FlagNdefTypeVcardOrganization,
+		FlagNdefTypeVcardPhoneCell,
+		FlagNdefTypeVcardPhoneHome,
+		FlagNdefTypeVcardPhoneWork,
+		FlagNdefTypeTitle,
+		FlagNdefTypeVcardSite,
+	},
+	NdefTypeMime:   {FlagNdefTypeType, FlagNdefTypeMimeFormat, FlagNdefTypeMimeContent},
+	NdefTypePhone:  {FlagNdefTypePhone},
+	NdefTypeGeo:    {FlagNdefTypeGeoLat, FlagNdefTypeGeoLon},
+	NdefTypeAar:    {FlagNdefTypeAarPackage},
+	NdefTypePoster: {FlagNdefTypeTitle, FlagNdefUri},
+}
+
 type NdefPayload interface{}
 
 type NdefRecordPayloadRaw struct {
@@ -0,0 +1,139 @@
+package models
+
+import (
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+)
+
+type OutputFormat = string
+
+const (
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJson   OutputFormat = "json"
+	OutputFormatNdjson OutputFormat = "ndjson"
+)
+
+// VersionOutput is printed by the version command in machine-readable formats
+type VersionOutput struct {
+	Cli    CliInfo           `json:"cli"`
+	Server apiModels.AppInfo `json:"server"`
+}
+
+type CliInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	SDKInfo   string `json:"sdk_info"`
+	Platform  string `json:"platform"`
+	BuildTime string `json:"build_time"`
+}
+
+// ListOutput is printed by paginated list commands in json format
+type ListOutput struct {
+	Total  int           `json:"total"`
+	Length int           `json:"length"`
+	Limit  int           `json:"limit"`
+	Offset int           `json:"offset"`
+	Items  []interface{} `json:"items"`
+}
+
+// NdefEncodedOutput is printed by the ndef encode command in machine-readable formats
+type NdefEncodedOutput struct {
+	Hex    string `json:"hex"`
+	Length int    `json:"length"`
+	Tlv    bool   `json:"tlv"`
+}
+
+// DumpAnalysisOutput is printed by the dump analyze command in machine-readable formats
+type DumpAnalysisOutput struct {
+	Family      string              `json:"family"`
+	Model       string              `json:"model"`
+	Uid         string              `json:"uid"`
+	Pages       []DumpPageOutput    `json:"pages"`
+	Warnings    []string            `json:"warnings,omitempty"`
+	NdefRecords []map[string]string `json:"ndef_records"`
+	NdefError   string              `json:"ndef_error,omitempty"`
+}
+
+type DumpPageOutput struct {
+	Page   int      `json:"page"`
+	Data   string   `json:"data"`
+	Kind   string   `json:"kind"`
+	Region string   `json:"region"`
+	Notes  []string `json:"notes,omitempty"`
+}
+
+// DumpDiffOutput is printed by the dump diff command in machine-readable formats
+type DumpDiffOutput struct {
+	A       string               `json:"a"`
+	B       string               `json:"b"`
+	UidA    string               `json:"uid_a"`
+	UidB    string               `json:"uid_b"`
+	Equal   bool                 `json:"equal"`
+	Pages   []DumpPageDiffOutput `json:"pages"`
+	Summary map[string]int       `json:"summary"`
+}
+
+type DumpPageDiffOutput struct {
+	Page    int    `json:"page"`
+	Kind    string `json:"kind"`
+	Region  string `json:"region"`
+	A       string `json:"a"`
+	B       string `json:"b"`
+	Changed []int  `json:"changed"`
+}
+
+// RestoreVerificationOutput is printed by the restore command in machine-readable formats
+type RestoreVerificationOutput struct {
+	Uid        string               `json:"uid"`
+	Pages      int                  `json:"pages"`
+	Verified   bool                 `json:"verified"`
+	Mismatches []DumpPageDiffOutput `json:"mismatches"`
+}
+
+// WriteVerificationOutput is printed by the write command with verify flag in machine-readable formats
+type WriteVerificationOutput struct {
+	Uid        string                     `json:"uid"`
+	Records    int                        `json:"records"`
+	Verified   bool                       `json:"verified"`
+	Mismatches []NdefRecordMismatchOutput `json:"mismatches"`
+}
+
+// NdefRecordMismatchOutput is a record read back from the tag which differs from the written one
+type NdefRecordMismatchOutput struct {
+	Record   int    `json:"record"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// JobDeletedOutput is printed by the jobs rm command in machine-readable formats
+type JobDeletedOutput struct {
+	JobID   string `json:"job_id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// ConfigOutput is printed by the config show command in machine-readable formats
+type ConfigOutput struct {
+	File    string              `json:"file"`
+	Profile string              `json:"profile"`
+	Values  []ConfigValueOutput `json:"values"`
+}
+
+// ConfigValueOutput is a flag value with its source: environment variable, profile or defaults of config file
+type ConfigValueOutput struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// JobFileOutput is printed by jobs lint command and run command with validate flag in machine-readable formats
+type JobFileOutput struct {
+	File     string                 `json:"file"`
+	Valid    bool                   `json:"valid"`
+	Jobs     int                    `json:"jobs"`
+	Runs     int                    `json:"runs"`
+	Problems []JobFileProblemOutput `json:"problems"`
+}
+
+type JobFileProblemOutput struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
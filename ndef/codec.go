@@ -0,0 +1,250 @@
+package ndef
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+// Record header flags
+const (
+	FlagMB byte = 0x80 // message begin
+	FlagME byte = 0x40 // message end
+	FlagCF byte = 0x20 // chunked record
+	FlagSR byte = 0x10 // short record with one byte payload length
+	FlagIL byte = 0x08 // ID length is present
+
+	tnfMask byte = 0x07
+)
+
+// Type name formats
+const (
+	TnfEmpty byte = iota
+	TnfWellKnown
+	TnfMedia
+	TnfAbsoluteUri
+	TnfExternal
+	TnfUnknown
+	TnfUnchanged
+	TnfReserved
+)
+
+// TLV blocks of NFC Forum tags memory
+const (
+	TlvNull        byte = 0x00
+	TlvNdefMessage byte = 0x03
+	TlvTerminator  byte = 0xFE
+)
+
+// Record is a binary NDEF record. Header holds flags the record was decoded with and is ignored on encoding
+type Record struct {
+	Header  byte
+	Tnf     byte
+	Type    []byte
+	ID      []byte
+	Payload []byte
+}
+
+// EncodeMessage encodes records to NDEF message. MB, ME, SR and IL flags are set from records position and lengths.
+// Records are never chunked
+func EncodeMessage(records []Record) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, r := range records {
+		if len(r.Type) > 0xFF {
+			return nil, errors.New(fmt.Sprintf("Record %d type is too long", i+1))
+		}
+		if len(r.ID) > 0xFF {
+			return nil, errors.New(fmt.Sprintf("Record %d ID is too long", i+1))
+		}
+
+		header := r.Tnf & tnfMask
+		if i == 0 {
+			header |= FlagMB
+		}
+		if i == len(records)-1 {
+			header |= FlagME
+		}
+		if len(r.Payload) <= 0xFF {
+			header |= FlagSR
+		}
+		if len(r.ID) > 0 {
+			header |= FlagIL
+		}
+
+		buf.WriteByte(header)
+		buf.WriteByte(byte(len(r.Type)))
+		if header&FlagSR != 0 {
+			buf.WriteByte(byte(len(r.Payload)))
+		} else {
+			var l [4]byte
+			binary.BigEndian.PutUint32(l[:], uint32(len(r.Payload)))
+			buf.Write(l[:])
+		}
+		if header&FlagIL != 0 {
+			buf.WriteByte(byte(len(r.ID)))
+		}
+		buf.Write(r.Type)
+		buf.Write(r.ID)
+		buf.Write(r.Payload)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// DecodeMessage decodes NDEF message up to the record with ME flag. Chunked records are joined into one record
+func DecodeMessage(data []byte) ([]Record, error) {
+	// empty NDEF message TLV is written to formatted tags
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var records []Record
+	var chunk *Record
+	pos := 0
+
+	read := func(n int) ([]byte, error) {
+		if n < 0 || pos+n > len(data) {
+			return nil, errors.New(fmt.Sprintf("Unexpected end of NDEF message at byte %d", pos))
+		}
+		res := data[pos : pos+n]
+		pos += n
+		return res, nil
+	}
+
+	for pos < len(data) {
+		h, err := read(2)
+		if err != nil {
+			return nil, err
+		}
+		header, typeLen := h[0], int(h[1])
+
+		if len(records) == 0 && chunk == nil && header&FlagMB == 0 {
+			return nil, errors.New("First record of NDEF message doesn't have MB flag")
+		}
+
+		var payloadLen int
+		if header&FlagSR != 0 {
+			l, err := read(1)
+			if err != nil {
+				return nil, err
+			}
+			payloadLen = int(l[0])
+		} else {
+			l, err := read(4)
+			if err != nil {
+				return nil, err
+			}
+			payloadLen = int(binary.BigEndian.Uint32(l))
+		}
+
+		idLen := 0
+		if header&FlagIL != 0 {
+			l, err := read(1)
+			if err != nil {
+				return nil, err
+			}
+			idLen = int(l[0])
+		}
+
+		r := Record{Header: header, Tnf: header & tnfMask}
+		if r.Type, err = read(typeLen); err != nil {
+			return nil, err
+		}
+		if r.ID, err = read(idLen); err != nil {
+			return nil, err
+		}
+		if r.Payload, err = read(payloadLen); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case chunk != nil:
+			if r.Tnf != TnfUnchanged || len(r.Type) != 0 {
+				return nil, errors.New(fmt.Sprintf("Chunk of record %d should have unchanged TNF and empty type", len(records)+1))
+			}
+			chunk.Payload = append(chunk.Payload, r.Payload...)
+			if header&FlagCF == 0 {
+				chunk.Header |= header & FlagME
+				records = append(records, *chunk)
+				chunk = nil
+			}
+		case header&FlagCF != 0:
+			r.Payload = append([]byte{}, r.Payload...)
+			chunk = &r
+		default:
+			records = append(records, r)
+		}
+
+		if header&FlagME != 0 && chunk == nil {
+			return records, nil
+		}
+	}
+
+	return nil, errors.New("NDEF message end is missing. Last record doesn't have ME flag")
+}
+
+// WrapTlv wraps NDEF message into NDEF message TLV followed by terminator TLV as it is stored in tag memory
+func WrapTlv(message []byte) []byte {
+	res := []byte{TlvNdefMessage}
+	if len(message) < 0xFF {
+		res = append(res, byte(len(message)))
+	} else {
+		res = append(res, 0xFF, byte(len(message)>>8), byte(len(message)))
+	}
+	res = append(res, message...)
+
+	return append(res, TlvTerminator)
+}
+
+// UnwrapTlv returns value of the first NDEF message TLV skipping other TLV blocks
+func UnwrapTlv(data []byte) ([]byte, error) {
+	pos := 0
+	for pos < len(data) {
+		t := data[pos]
+		pos++
+
+		switch t {
+		case TlvNull:
+			continue
+		case TlvTerminator:
+			return nil, errors.New("NDEF message TLV is not found")
+		}
+
+		if pos >= len(data) {
+			break
+		}
+		l := int(data[pos])
+		pos++
+		if l == 0xFF {
+			if pos+2 > len(data) {
+				break
+			}
+			l = int(binary.BigEndian.Uint16(data[pos : pos+2]))
+			pos += 2
+		}
+
+		if pos+l > len(data) {
+			return nil, errors.New(fmt.Sprintf("TLV 0x%02X length %d exceeds data size", t, l))
+		}
+		if t == TlvNdefMessage {
+			return data[pos : pos+l], nil
+		}
+		pos += l
+	}
+
+	return nil, errors.New("NDEF message TLV is not found")
+}
+
+// DecodeData decodes NDEF message given either as raw message or as TLV blocks of tag memory
+func DecodeData(data []byte) ([]Record, error) {
+	if len(data) > 0 && data[0]&FlagMB == 0 {
+		var err error
+		data, err = UnwrapTlv(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return DecodeMessage(data)
+}
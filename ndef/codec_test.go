@@ -0,0 +1,76 @@
+package ndef
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/models"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	data, err := Encode([]NdefPayload{NdefRecordPayloadUrl{Url: "https://tagl.me"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xD1, 0x01, 0x08, 0x55, 0x04, 't', 'a', 'g', 'l', '.', 'm', 'e'}, data)
+
+	data, err = Encode([]NdefPayload{NdefRecordPayloadTypeText{Text: "hi", Lang: "English"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xD1, 0x01, 0x05, 0x54, 0x02, 'e', 'n', 'h', 'i'}, data)
+}
+
+func TestDecode(t *testing.T) {
+	payloads := []NdefPayload{
+		&NdefRecordPayloadUrl{Url: "https://tagl.me"},
+		&NdefRecordPayloadTypeText{Text: "Привет", Lang: "Russian"},
+		&NdefRecordPayloadPhone{PhoneNumber: "+78005553535"},
+		&NdefRecordPayloadGeo{Latitude: "55.75", Longitude: "37.61"},
+		&NdefRecordPayloadAar{PackageName: "me.tagl"},
+		&NdefRecordPayloadMime{Type: "application/octet-stream", Format: models.MimeFormatHex, ContentHEX: []byte{0x01, 0x02}},
+		&NdefRecordPayloadPoster{Title: "Tagl", Uri: "mailto:a@tagl.me"},
+		&NdefRecordPayloadVcard{FirstName: "John", LastName: "Doe", Email: "john@tagl.me", AddressCity: "Moscow"},
+	}
+
+	data, err := Encode(payloads)
+	assert.Nil(t, err)
+
+	decoded, records, err := Decode(WrapTlv(data))
+	assert.Nil(t, err)
+	assert.Len(t, records, len(payloads))
+	assert.Equal(t, payloads, decoded)
+}
+
+func TestDecodeMessage(t *testing.T) {
+	// text record split into two chunks
+	chunked := []byte{
+		0xB1, 0x01, 0x03, 0x54, 0x02, 'e', 'n',
+		0x56, 0x00, 0x02, 'h', 'i',
+	}
+	records, err := DecodeMessage(chunked)
+	assert.Nil(t, err)
+	assert.Equal(t, []Record{{Header: 0xF1, Tnf: TnfWellKnown, Type: []byte("T"), ID: []byte{}, Payload: []byte{0x02, 'e', 'n', 'h', 'i'}}}, records)
+
+	_, err = DecodeMessage([]byte{0x51, 0x01, 0x00, 0x54})
+	assert.EqualError(t, err, "First record of NDEF message doesn't have MB flag")
+
+	_, err = DecodeMessage([]byte{0x91, 0x01, 0x00, 0x54})
+	assert.EqualError(t, err, "NDEF message end is missing. Last record doesn't have ME flag")
+
+	_, err = DecodeMessage([]byte{0xD1, 0x01, 0x05, 0x54, 0x02})
+	assert.EqualError(t, err, "Unexpected end of NDEF message at byte 4")
+}
+
+func TestUnwrapTlv(t *testing.T) {
+	message := []byte{0xD0, 0x00, 0x00}
+
+	data, err := UnwrapTlv([]byte{0x00, 0x01, 0x03, 0xA0, 0x0C, 0x34, 0x03, 0x03, 0xD0, 0x00, 0x00, 0xFE})
+	assert.Nil(t, err)
+	assert.Equal(t, message, data)
+
+	long := make([]byte, 300)
+	wrapped := WrapTlv(long)
+	assert.Equal(t, []byte{0x03, 0xFF, 0x01, 0x2C}, wrapped[:4])
+	data, err = UnwrapTlv(wrapped)
+	assert.Nil(t, err)
+	assert.Equal(t, long, data)
+
+	_, err = UnwrapTlv([]byte{0x00, 0xFE})
+	assert.EqualError(t, err, "NDEF message TLV is not found")
+}
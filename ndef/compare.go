@@ -0,0 +1,60 @@
+package ndef
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+)
+
+// RecordMismatch is a record which differs in written and read messages. Missing record is an empty string
+type RecordMismatch struct {
+	Index    int
+	Expected string
+	Actual   string
+}
+
+// CompareMessage compares records read from the tag with the written ones. Records are equal when their API resources are equal
+func CompareMessage(expected []NdefPayload, actual []ndefconv.NdefRecord) []RecordMismatch {
+	size := len(expected)
+	if len(actual) > size {
+		size = len(actual)
+	}
+
+	var res []RecordMismatch
+	for i := 0; i < size; i++ {
+		var e, a *ndefconv.NdefRecord
+		if i < len(expected) {
+			r := expected[i].ToRecord()
+			e = &r
+		}
+		if i < len(actual) {
+			a = &actual[i]
+		}
+
+		if e != nil && a != nil && recordsEqual(*e, *a) {
+			continue
+		}
+		res = append(res, RecordMismatch{Index: i, Expected: describeRecord(e), Actual: describeRecord(a)})
+	}
+
+	return res
+}
+
+func recordsEqual(a, b ndefconv.NdefRecord) bool {
+	if a.Type != b.Type || a.Data == nil || b.Data == nil {
+		return false
+	}
+
+	encodedA, errA := json.Marshal(a.ToResource())
+	encodedB, errB := json.Marshal(b.ToResource())
+
+	return errA == nil && errB == nil && string(encodedA) == string(encodedB)
+}
+
+func describeRecord(r *ndefconv.NdefRecord) string {
+	if r == nil || r.Data == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s (%s)", r.Data.String(), r.Type.String())
+}
@@ -211,3 +211,24 @@ func TestNdefRecordPayloadVcard_ToRecord(t *testing.T) {
 		a.ToRecord(),
 	)
 }
+
+func TestCompareMessage(t *testing.T) {
+	written := []NdefPayload{
+		NdefRecordPayloadUrl{Url: "https://tagl.me"},
+		NdefRecordPayloadTypeText{Text: "hello", Lang: "en"},
+	}
+
+	read := []ndefconv.NdefRecord{written[0].ToRecord(), written[1].ToRecord()}
+	assert.Empty(t, CompareMessage(written, read))
+
+	read[1] = NdefRecordPayloadTypeText{Text: "hell", Lang: "en"}.ToRecord()
+	mismatches := CompareMessage(written, read)
+	assert.Len(t, mismatches, 1)
+	assert.Equal(t, 1, mismatches[0].Index)
+	assert.Contains(t, mismatches[0].Expected, "hello")
+	assert.Contains(t, mismatches[0].Actual, "hell")
+
+	mismatches = CompareMessage(written, read[:1])
+	assert.Len(t, mismatches, 1)
+	assert.Equal(t, "", mismatches[0].Actual)
+}
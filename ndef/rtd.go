@@ -0,0 +1,412 @@
+package ndef
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Record types of well-known, media and external records
+const (
+	RtdUri    = "U"
+	RtdText   = "T"
+	RtdPoster = "Sp"
+	MimeVcard = "text/vcard"
+	ExtAar    = "android.com:pkg"
+)
+
+// UriPrefixes is the URI identifier code table of NFC Forum URI record type definition
+var UriPrefixes = []string{
+	"",
+	"http://www.",
+	"https://www.",
+	"http://",
+	"https://",
+	"tel:",
+	"mailto:",
+	"ftp://anonymous:anonymous@",
+	"ftp://ftp.",
+	"ftps://",
+	"sftp://",
+	"smb://",
+	"nfs://",
+	"ftp://",
+	"dav://",
+	"news:",
+	"telnet://",
+	"imap:",
+	"rtsp://",
+	"urn:",
+	"pop:",
+	"sip:",
+	"sips:",
+	"tftp:",
+	"btspp://",
+	"btl2cap://",
+	"btgoep://",
+	"tcpobex://",
+	"irdaobex://",
+	"file://",
+	"urn:epc:id:",
+	"urn:epc:tag:",
+	"urn:epc:pat:",
+	"urn:epc:raw:",
+	"urn:epc:",
+	"urn:nfc:",
+}
+
+// Encode encodes CLI records into NDEF message
+func Encode(payloads []NdefPayload) ([]byte, error) {
+	records := make([]Record, len(payloads))
+	for i, p := range payloads {
+		var err error
+		records[i], err = NewRecord(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Record %d", i+1)
+		}
+	}
+
+	return EncodeMessage(records)
+}
+
+// NewRecord converts CLI record into binary NDEF record
+func NewRecord(p NdefPayload) (Record, error) {
+	if v := reflect.ValueOf(p); v.Kind() == reflect.Ptr && !v.IsNil() {
+		p = v.Elem().Interface().(NdefPayload)
+	}
+
+	switch p := p.(type) {
+	case NdefRecordPayloadRaw:
+		if p.Tnf < 0 || p.Tnf > int(TnfReserved) {
+			return Record{}, errors.New("Wrong tnf value. Can be only from 0 to 7")
+		}
+		return Record{Tnf: byte(p.Tnf), Type: []byte(p.Type), ID: []byte(p.ID), Payload: p.Payload}, nil
+	case NdefRecordPayloadUrl:
+		return newUriRecord(p.Url), nil
+	case NdefRecordPayloadUri:
+		return newUriRecord(p.Uri), nil
+	case NdefRecordPayloadPhone:
+		return newUriRecord("tel:" + p.PhoneNumber), nil
+	case NdefRecordPayloadGeo:
+		return newUriRecord("geo:" + p.Latitude + "," + p.Longitude), nil
+	case NdefRecordPayloadTypeText:
+		return newTextRecord(p.Text, p.Lang), nil
+	case NdefRecordPayloadPoster:
+		payload, err := EncodeMessage([]Record{newUriRecord(p.Uri), newTextRecord(p.Title, "English")})
+		if err != nil {
+			return Record{}, err
+		}
+		return Record{Tnf: TnfWellKnown, Type: []byte(RtdPoster), Payload: payload}, nil
+	case NdefRecordPayloadVcard:
+		return Record{Tnf: TnfMedia, Type: []byte(MimeVcard), Payload: encodeVcard(p)}, nil
+	case NdefRecordPayloadMime:
+		payload := []byte(p.ContentASCII)
+		if p.Format == models.MimeFormatHex {
+			payload = p.ContentHEX
+		}
+		return Record{Tnf: TnfMedia, Type: []byte(p.Type), Payload: payload}, nil
+	case NdefRecordPayloadAar:
+		return Record{Tnf: TnfExternal, Type: []byte(ExtAar), Payload: []byte(p.PackageName)}, nil
+	}
+
+	return Record{}, errors.New(fmt.Sprintf("Can't encode record of type %T", p))
+}
+
+// Decode decodes NDEF message or tag memory TLV blocks into CLI records
+func Decode(data []byte) ([]NdefPayload, []Record, error) {
+	records, err := DecodeData(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payloads := make([]NdefPayload, len(records))
+	for i, r := range records {
+		payloads[i], err = RecordToPayload(r)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Record %d", i+1)
+		}
+	}
+
+	return payloads, records, nil
+}
+
+// RecordToPayload converts binary NDEF record into CLI record. Records of unknown types are returned as raw records
+func RecordToPayload(r Record) (NdefPayload, error) {
+	t := string(r.Type)
+
+	switch {
+	case r.Tnf == TnfWellKnown && t == RtdUri:
+		uri, err := decodeUri(r.Payload)
+		if err != nil {
+			return nil, err
+		}
+		return uriToPayload(uri), nil
+	case r.Tnf == TnfWellKnown && t == RtdText:
+		text, lang, err := decodeText(r.Payload)
+		if err != nil {
+			return nil, err
+		}
+		return &NdefRecordPayloadTypeText{Text: text, Lang: lang}, nil
+	case r.Tnf == TnfWellKnown && t == RtdPoster:
+		return decodePoster(r.Payload)
+	case r.Tnf == TnfMedia && (strings.EqualFold(t, MimeVcard) || strings.EqualFold(t, "text/x-vcard")):
+		return decodeVcard(r.Payload), nil
+	case r.Tnf == TnfMedia:
+		if isPrintable(r.Payload) {
+			return &NdefRecordPayloadMime{Type: t, Format: models.MimeFormatASCII, ContentASCII: string(r.Payload)}, nil
+		}
+		return &NdefRecordPayloadMime{Type: t, Format: models.MimeFormatHex, ContentHEX: r.Payload}, nil
+	case r.Tnf == TnfExternal && t == ExtAar:
+		return &NdefRecordPayloadAar{PackageName: string(r.Payload)}, nil
+	}
+
+	return &NdefRecordPayloadRaw{Tnf: int(r.Tnf), Type: t, ID: string(r.ID), Payload: r.Payload}, nil
+}
+
+func newUriRecord(uri string) Record {
+	code := 0
+	for i, prefix := range UriPrefixes {
+		if len(prefix) > len(UriPrefixes[code]) && strings.HasPrefix(uri, prefix) {
+			code = i
+		}
+	}
+
+	payload := append([]byte{byte(code)}, uri[len(UriPrefixes[code]):]...)
+
+	return Record{Tnf: TnfWellKnown, Type: []byte(RtdUri), Payload: payload}
+}
+
+func decodeUri(payload []byte) (string, error) {
+	if len(payload) == 0 {
+		return "", errors.New("URI record payload is empty")
+	}
+	if int(payload[0]) >= len(UriPrefixes) {
+		return "", errors.New(fmt.Sprintf("Unknown URI identifier code 0x%02X", payload[0]))
+	}
+
+	return UriPrefixes[payload[0]] + string(payload[1:]), nil
+}
+
+func uriToPayload(uri string) NdefPayload {
+	switch {
+	case strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://"):
+		return &NdefRecordPayloadUrl{Url: uri}
+	case strings.HasPrefix(uri, "tel:"):
+		return &NdefRecordPayloadPhone{PhoneNumber: strings.TrimPrefix(uri, "tel:")}
+	case strings.HasPrefix(uri, "geo:"):
+		coords := strings.SplitN(strings.TrimPrefix(uri, "geo:"), ",", 2)
+		if len(coords) == 2 {
+			return &NdefRecordPayloadGeo{Latitude: coords[0], Longitude: strings.SplitN(coords[1], ";", 2)[0]}
+		}
+	}
+
+	return &NdefRecordPayloadUri{Uri: uri}
+}
+
+// newTextRecord builds RTD Text record with UTF-8 text. Status byte holds length of IANA language code
+func newTextRecord(text, lang string) Record {
+	code := ndefconv.LangToCode(lang)
+	payload := append([]byte{byte(len(code))}, code...)
+	payload = append(payload, text...)
+
+	return Record{Tnf: TnfWellKnown, Type: []byte(RtdText), Payload: payload}
+}
+
+func decodeText(payload []byte) (text string, lang string, err error) {
+	if len(payload) == 0 {
+		return "", "", errors.New("Text record payload is empty")
+	}
+
+	status := payload[0]
+	codeLen := int(status & 0x3F)
+	if 1+codeLen > len(payload) {
+		return "", "", errors.New("Text record language code length exceeds payload size")
+	}
+
+	lang = ndefconv.CodeToLang(strings.ToLower(string(payload[1 : 1+codeLen])))
+	body := payload[1+codeLen:]
+	if status&0x80 == 0 {
+		return string(body), lang, nil
+	}
+
+	// UTF-16 text is big endian unless byte order mark says otherwise
+	bigEndian := true
+	if len(body) >= 2 && body[0] == 0xFF && body[1] == 0xFE {
+		bigEndian = false
+		body = body[2:]
+	} else if len(body) >= 2 && body[0] == 0xFE && body[1] == 0xFF {
+		body = body[2:]
+	}
+
+	units := make([]uint16, len(body)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(body[2*i])<<8 | uint16(body[2*i+1])
+		} else {
+			units[i] = uint16(body[2*i+1])<<8 | uint16(body[2*i])
+		}
+	}
+
+	return string(utf16.Decode(units)), lang, nil
+}
+
+func decodePoster(payload []byte) (NdefPayload, error) {
+	records, err := DecodeMessage(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't decode smart poster message")
+	}
+
+	res := NdefRecordPayloadPoster{}
+	for _, r := range records {
+		if r.Tnf != TnfWellKnown {
+			continue
+		}
+
+		switch string(r.Type) {
+		case RtdUri:
+			if len(res.Uri) == 0 {
+				res.Uri, err = decodeUri(r.Payload)
+			}
+		case RtdText:
+			if len(res.Title) == 0 {
+				res.Title, _, err = decodeText(r.Payload)
+			}
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't decode smart poster message")
+		}
+	}
+
+	return &res, nil
+}
+
+func encodeVcard(v NdefRecordPayloadVcard) []byte {
+	var buf bytes.Buffer
+	line := func(name string, values ...string) {
+		for _, v := range values {
+			if len(v) > 0 {
+				for i := range values {
+					values[i] = escapeVcard(values[i])
+				}
+				buf.WriteString(name + ":" + strings.Join(values, ";") + "\r\n")
+				return
+			}
+		}
+	}
+
+	buf.WriteString("BEGIN:VCARD\r\nVERSION:3.0\r\n")
+	line("N", v.LastName, v.FirstName, "", "", "")
+	line("FN", strings.TrimSpace(v.FirstName+" "+v.LastName))
+	line("ORG", v.Organization)
+	line("TITLE", v.Title)
+	line("ADR", "", "", v.AddressStreet, v.AddressCity, v.AddressRegion, v.AddressPostalCode, v.AddressCountry)
+	line("TEL;TYPE=CELL", v.PhoneCell)
+	line("TEL;TYPE=HOME", v.PhoneHome)
+	line("TEL;TYPE=WORK", v.PhoneWork)
+	line("EMAIL", v.Email)
+	line("URL", v.Site)
+	buf.WriteString("END:VCARD\r\n")
+
+	return buf.Bytes()
+}
+
+func decodeVcard(payload []byte) *NdefRecordPayloadVcard {
+	res := NdefRecordPayloadVcard{}
+
+	// folded lines are continued with leading space or tab
+	src := strings.NewReplacer("\r\n ", "", "\r\n\t", "", "\n ", "", "\n\t", "").Replace(string(payload))
+	for _, l := range strings.Split(src, "\n") {
+		kv := strings.SplitN(strings.TrimRight(l, "\r"), ":", 2)
+		if len(kv) < 2 {
+			continue
+		}
+
+		params := strings.Split(strings.ToUpper(kv[0]), ";")
+		values := splitVcard(kv[1])
+		get := func(i int) string {
+			if i < len(values) {
+				return values[i]
+			}
+			return ""
+		}
+
+		switch params[0] {
+		case "N":
+			res.LastName, res.FirstName = get(0), get(1)
+		case "ORG":
+			res.Organization = get(0)
+		case "TITLE":
+			res.Title = get(0)
+		case "ADR":
+			res.AddressStreet, res.AddressCity, res.AddressRegion, res.AddressPostalCode, res.AddressCountry = get(2), get(3), get(4), get(5), get(6)
+		case "TEL":
+			p := strings.Join(params[1:], ";")
+			switch {
+			case strings.Contains(p, "HOME"):
+				res.PhoneHome = get(0)
+			case strings.Contains(p, "WORK"):
+				res.PhoneWork = get(0)
+			default:
+				res.PhoneCell = get(0)
+			}
+		case "EMAIL":
+			res.Email = get(0)
+		case "URL":
+			res.Site = get(0)
+		}
+	}
+
+	return &res
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+func escapeVcard(s string) string {
+	return vcardEscaper.Replace(s)
+}
+
+// splitVcard splits structured vCard value by not escaped semicolons and unescapes components
+func splitVcard(s string) []string {
+	var res []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r == 'n' || r == 'N' {
+				r = '\n'
+			}
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ';':
+			res = append(res, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+
+	return append(res, cur.String())
+}
+
+func isPrintable(b []byte) bool {
+	if !utf8.Valid(b) {
+		return false
+	}
+	for _, r := range string(b) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -3,35 +3,49 @@ package repository
 import (
 	"encoding/base64"
 	"fmt"
+	"github.com/pkg/errors"
 	"github.com/taglme/nfc-cli/models"
 	"github.com/taglme/nfc-cli/ndef"
 	"github.com/taglme/nfc-goclient/pkg/client"
 	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+	"log"
+	"net/http"
 )
 
 type RepositoryService struct {
-	client *client.Client
+	client     *client.Client
+	url        string
+	httpClient *http.Client
+	// prefixAdapter is set when event output lines are prefixed with adapter name
+	prefixAdapter bool
+	// ws replaces the events connection of the client which can't be disconnected while events are read
+	ws *wsService
+	// wsStop is closed when events connection is stopped and shouldn't be restored
+	wsStop chan struct{}
 }
 
 func New(c **client.Client) *RepositoryService {
 	return &RepositoryService{
 		client: *c,
+		ws:     newWsService((*c).Ws.ConnString()),
 	}
 }
 
-func (s *RepositoryService) GetVersion() (apiModels.AppInfo, error) {
+func (s *RepositoryService) GetVersion(withOutput bool) (apiModels.AppInfo, error) {
 	i, err := s.client.About.Get()
 	if err != nil {
 		return i, err
 	}
 
-	s.printAppInfo(i)
+	if withOutput {
+		s.printAppInfo(i)
+	}
 	return i, err
 }
 
-func (s *RepositoryService) GetAdapters(withOutput bool) ([]apiModels.Adapter, error) {
-	a, err := s.client.Adapters.GetAll()
+func (s *RepositoryService) GetAdapters(adapterType *apiModels.AdapterType, withOutput bool) ([]apiModels.Adapter, error) {
+	a, err := s.client.Adapters.GetFiltered(adapterType)
 	if err != nil {
 		return a, err
 	}
@@ -43,41 +57,146 @@ func (s *RepositoryService) GetAdapters(withOutput bool) ([]apiModels.Adapter, e
 	return a, err
 }
 
-func (s *RepositoryService) GetJob(adapterId, id string) (apiModels.Job, error) {
-	return s.client.Jobs.Get(adapterId, id)
+func (s *RepositoryService) GetTags(adapterId string, tagType *apiModels.TagType, withOutput bool) ([]apiModels.Tag, error) {
+	short, err := s.client.Tags.GetAll(adapterId, tagType)
+	if err != nil {
+		return short, err
+	}
+
+	// tags list contains short resources only, so details are requested for every tag
+	tags := make([]apiModels.Tag, len(short))
+	for i, t := range short {
+		tags[i], err = s.client.Tags.Get(adapterId, t.TagID)
+		if err != nil {
+			return tags, err
+		}
+	}
+
+	if withOutput {
+		s.printTags(tags)
+	}
+
+	return tags, err
+}
+
+func (s *RepositoryService) GetTag(adapterId, tagId string, withOutput bool) (apiModels.Tag, error) {
+	t, err := s.client.Tags.Get(adapterId, tagId)
+	if err != nil {
+		return t, err
+	}
+
+	if withOutput {
+		s.printTag(t)
+	}
+
+	return t, err
+}
+
+func (s *RepositoryService) GetJobs(adapterId string, filter client.JobFilter, withOutput bool) ([]apiModels.Job, apiModels.PageInfo, error) {
+	j, p, err := s.client.Jobs.GetFiltered(adapterId, filter)
+	if err != nil {
+		return j, p, err
+	}
+
+	if withOutput {
+		s.printJobs(j, p)
+	}
+
+	return j, p, err
+}
+
+func (s *RepositoryService) GetJob(adapterId, id string, withOutput bool) (apiModels.Job, error) {
+	j, err := s.client.Jobs.Get(adapterId, id)
+	if err != nil {
+		return j, err
+	}
+
+	if withOutput {
+		s.printJob(j)
+	}
+
+	return j, err
+}
+
+func (s *RepositoryService) DeleteJob(adapterId, id string) error {
+	return s.client.Jobs.Delete(adapterId, id)
+}
+
+func (s *RepositoryService) UpdateJobStatus(adapterId, id string, status apiModels.JobStatus, withOutput bool) (apiModels.Job, error) {
+	j, err := s.client.Jobs.UpdateStatus(adapterId, id, status)
+	if err != nil {
+		return j, err
+	}
+
+	if withOutput {
+		s.printJob(j)
+	}
+
+	return j, err
+}
+
+func (s *RepositoryService) GetEvents(adapterId *string, filter client.EventFilter, withOutput bool) ([]apiModels.Event, apiModels.PageInfo, error) {
+	e, p, err := s.client.Events.GetFiltered(adapterId, filter)
+	if err != nil {
+		return e, p, err
+	}
+
+	if withOutput {
+		s.printEvents(e, p)
+	}
+
+	return e, p, err
 }
 
 func (s *RepositoryService) DeleteAdapterJobs(adapterId string) error {
 	return s.client.Jobs.DeleteAll(adapterId)
 }
 
+// AddJobFromFile adds all jobs of the file or none of them. When the server rejects the job, jobs added before it
+// are deleted and the line of the job is reported
 func (s *RepositoryService) AddJobFromFile(adapterId string, filename string, p models.GenericJobParams) (int, error) {
-	newJobs, err := s.readFromFile(filename)
+	entries, err := s.readFromFile(filename, p.Vars)
 	if err != nil {
 		return 0, err
 	}
 
-	fmt.Printf("Loaded %d jobs.\n", len(newJobs))
+	fmt.Printf("Loaded %d jobs.\n", len(entries))
 
 	runs := 0
-	for _, newJob := range newJobs {
-		if p.Expire != 60 {
-			newJob.ExpireAfter = p.Expire
+	var added []string
+	for _, entry := range entries {
+		newJob := entry.Resolve(p.Expire, p.JobName)
+		j, err := s.client.Jobs.Add(adapterId, newJob)
+		if len(j.JobID) > 0 {
+			added = append(added, j.JobID)
 		}
-
-		if len(p.JobName) > 0 {
-			newJob.JobName = p.JobName
+		if err != nil {
+			msg := fmt.Sprintf("Can't add job %s from %s:%d", newJob.JobName, filename, entry.Line)
+			if failed := s.deleteJobs(adapterId, added); failed > 0 {
+				msg += fmt.Sprintf(", %d of %d added jobs can't be deleted", failed, len(added))
+			} else if len(added) > 0 {
+				msg += fmt.Sprintf(", %d added jobs are deleted", len(added))
+			}
+			return 0, errors.Wrap(err, msg)
 		}
-
 		runs += newJob.Repeat
+	}
 
-		_, err := s.client.Jobs.Add(adapterId, newJob)
+	return runs, nil
+}
+
+// deleteJobs deletes jobs by IDs and returns a number of jobs which can't be deleted
+func (s *RepositoryService) deleteJobs(adapterId string, ids []string) int {
+	failed := 0
+	for _, id := range ids {
+		err := s.client.Jobs.Delete(adapterId, id)
 		if err != nil {
-			return len(newJobs), err
+			log.Printf("Can't delete job %s: %s", id, err)
+			failed++
 		}
 	}
 
-	return runs, err
+	return failed
 }
 
 func (s *RepositoryService) addJob(nj *apiModels.NewJob, adapterId string, auth []byte, export bool) (*apiModels.Job, *apiModels.NewJob, error) {
@@ -114,22 +233,31 @@ func (s *RepositoryService) AddGenericJob(p models.GenericJobParams) (*apiModels
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
-func (s *RepositoryService) AddSetPwdJob(p models.GenericJobParams, password []byte) (*apiModels.Job, *apiModels.NewJob, error) {
+// AddSetPwdJob adds job which sets tag password. Commands are transmitted to the tag before the password is set
+func (s *RepositoryService) AddSetPwdJob(p models.GenericJobParams, password []byte, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error) {
+	nj := apiModels.NewJob{
+		JobName:     "Set tag password",
+		Repeat:      p.Repeat,
+		ExpireAfter: p.Expire,
+	}
+
+	for _, tx := range txCommands {
+		txStep := apiModels.JobStep{
+			Command: apiModels.CommandTransmitTag,
+			Params: apiModels.TransmitTagParams{
+				TxBytes: tx,
+			},
+		}
+		nj.Steps = append(nj.Steps, txStep.ToResource())
+	}
+
 	jobStep := apiModels.JobStep{
 		Command: apiModels.CommandSetPassword,
 		Params: apiModels.SetPasswordParams{
 			Password: password,
 		},
 	}
-
-	jobStepResource := jobStep.ToResource()
-
-	nj := apiModels.NewJob{
-		JobName:     "Set tag password",
-		Repeat:      p.Repeat,
-		ExpireAfter: p.Expire,
-		Steps:       []apiModels.JobStepResource{jobStepResource},
-	}
+	nj.Steps = append(nj.Steps, jobStep.ToResource())
 
 	if len(p.JobName) > 0 {
 		nj.JobName = p.JobName
@@ -173,15 +301,19 @@ func (s *RepositoryService) AddTransmitJob(p models.GenericJobParams, txBytes []
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
-func (s *RepositoryService) AddWriteJob(p models.GenericJobParams, r ndef.NdefPayload, protect bool) (*apiModels.Job, *apiModels.NewJob, error) {
+// AddWriteJob adds job which writes NDEF message. With verify the message is read back before the tag is locked
+func (s *RepositoryService) AddWriteJob(p models.GenericJobParams, records []ndef.NdefPayload, protect, verify bool) (*apiModels.Job, *apiModels.NewJob, error) {
 	var nj apiModels.NewJob
 
+	message := make([]ndefconv.NdefRecord, len(records))
+	for i, r := range records {
+		message[i] = r.ToRecord()
+	}
+
 	jobStep := apiModels.JobStep{
 		Command: apiModels.CommandWriteNdef,
 		Params: apiModels.WriteNdefParams{
-			Message: []ndefconv.NdefRecord{
-				r.ToRecord(),
-			},
+			Message: message,
 		},
 	}
 
@@ -193,6 +325,15 @@ func (s *RepositoryService) AddWriteJob(p models.GenericJobParams, r ndef.NdefPa
 	nj.ExpireAfter = p.Expire
 	nj.Steps = []apiModels.JobStepResource{jobStep.ToResource()}
 
+	if verify {
+		readStep := apiModels.JobStep{
+			Command: apiModels.CommandReadNdef,
+			Params:  apiModels.ReadNdefParams{},
+		}
+
+		nj.Steps = append(nj.Steps, readStep.ToResource())
+	}
+
 	if protect {
 		lockStep := apiModels.JobStep{
 			Command: apiModels.CommandLockPermanent,
@@ -205,6 +346,31 @@ func (s *RepositoryService) AddWriteJob(p models.GenericJobParams, r ndef.NdefPa
 	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
 }
 
+// AddRestoreJob adds job which transmits write commands to the tag and reads tag dump back for verification
+func (s *RepositoryService) AddRestoreJob(p models.GenericJobParams, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error) {
+	var nj apiModels.NewJob
+
+	nj.JobName = "Restore tag"
+	if len(p.JobName) > 0 {
+		nj.JobName = p.JobName
+	}
+	nj.Repeat = p.Repeat
+	nj.ExpireAfter = p.Expire
+
+	for _, tx := range txCommands {
+		jobStep := apiModels.JobStep{
+			Command: apiModels.CommandTransmitTag,
+			Params: apiModels.TransmitTagParams{
+				TxBytes: tx,
+			},
+		}
+		nj.Steps = append(nj.Steps, jobStep.ToResource())
+	}
+	nj.Steps = append(nj.Steps, MapCliCmdToApiJobSteps[models.CommandDump]...)
+
+	return s.addJob(&nj, p.AdapterId, p.Auth, p.Export)
+}
+
 var MapCliCmdToJobName = map[models.Command]string{
 	models.CommandRead:   "Read tag",
 	models.CommandDump:   "Dump tag",
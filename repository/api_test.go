@@ -220,7 +220,7 @@ func TestRepositoryService_AddSetPwdJob(t *testing.T) {
 	nfc := client.New("url")
 	rep := New(&nfc)
 
-	_, nj, err := rep.AddSetPwdJob(p, []byte{0xa6, 0x12, 0x66, 0xBA})
+	_, nj, err := rep.AddSetPwdJob(p, []byte{0xa6, 0x12, 0x66, 0xBA}, nil)
 	if err != nil {
 		t.Error(err)
 		log.Fatal(err)
@@ -231,6 +231,17 @@ func TestRepositoryService_AddSetPwdJob(t *testing.T) {
 	assert.Equal(t, apiModels.AuthPasswordParamsResource{Password: "phJmug=="}, nj.Steps[0].Params)
 	assert.Equal(t, apiModels.CommandSetPassword.String(), nj.Steps[1].Command)
 	assert.Equal(t, apiModels.SetPasswordParamsResource{Password: "phJmug=="}, nj.Steps[1].Params)
+
+	_, nj, err = rep.AddSetPwdJob(p, []byte{0xa6, 0x12, 0x66, 0xBA}, [][]byte{{0xA2, 0x2C, 0x12, 0x34, 0x00, 0x00}})
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, nj.Steps, 3)
+	assert.Equal(t, apiModels.CommandTransmitTag.String(), nj.Steps[1].Command)
+	assert.Equal(t, apiModels.TransmitTagParamsResource{TxBytes: "oiwSNAAA"}, nj.Steps[1].Params)
+	assert.Equal(t, apiModels.CommandSetPassword.String(), nj.Steps[2].Command)
 }
 
 func TestRepositoryService_AddTransmitJob_Adapter(t *testing.T) {
@@ -301,17 +312,27 @@ func TestRepositoryService_AddWriteJob(t *testing.T) {
 	nfc := client.New("url")
 	rep := New(&nfc)
 
-	_, nj, err := rep.AddWriteJob(p, ndef.NdefRecordPayloadUrl{Url: "http://url"}, false)
+	records := []ndef.NdefPayload{
+		ndef.NdefRecordPayloadUrl{Url: "http://url"},
+		ndef.NdefRecordPayloadAar{PackageName: "me.tagl"},
+	}
+	_, nj, err := rep.AddWriteJob(p, records, false, false)
 	if err != nil {
 		t.Error(err)
 		log.Fatal(err)
 	}
 
 	expectedNdef := apiModels.WriteNdefParamsResource{
-		Message: []ndefconv.NdefRecordResource{{
-			Type: ndefconv.NdefRecordPayloadTypeUrl.String(),
-			Data: ndefconv.NdefRecordPayloadUrlResource{Url: "http://url"},
-		}},
+		Message: []ndefconv.NdefRecordResource{
+			{
+				Type: ndefconv.NdefRecordPayloadTypeUrl.String(),
+				Data: ndefconv.NdefRecordPayloadUrlResource{Url: "http://url"},
+			},
+			{
+				Type: ndefconv.NdefRecordPayloadTypeAar.String(),
+				Data: ndefconv.NdefRecordPayloadAarResource{PackageName: "me.tagl"},
+			},
+		},
 	}
 
 	assert.Equal(t, "job name", nj.JobName)
@@ -333,7 +354,7 @@ func TestRepositoryService_AddWriteJob_Protect(t *testing.T) {
 	nfc := client.New("url")
 	rep := New(&nfc)
 
-	_, nj, err := rep.AddWriteJob(p, ndef.NdefRecordPayloadUrl{Url: "http://url"}, true)
+	_, nj, err := rep.AddWriteJob(p, []ndef.NdefPayload{ndef.NdefRecordPayloadUrl{Url: "http://url"}}, true, false)
 	if err != nil {
 		t.Error(err)
 		log.Fatal(err)
@@ -353,6 +374,54 @@ func TestRepositoryService_AddWriteJob_Protect(t *testing.T) {
 	assert.Equal(t, apiModels.LockPermanentParamsResource{}, nj.Steps[1].Params)
 }
 
+func TestRepositoryService_AddWriteJob_Verify(t *testing.T) {
+	p := models.GenericJobParams{
+		Cmd:       models.CommandWrite,
+		AdapterId: "adapterId",
+		Repeat:    1,
+		Expire:    60,
+		Export:    true,
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+
+	_, nj, err := rep.AddWriteJob(p, []ndef.NdefPayload{ndef.NdefRecordPayloadUrl{Url: "http://url"}}, true, true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, nj.Steps, 3)
+	assert.Equal(t, apiModels.CommandWriteNdef.String(), nj.Steps[0].Command)
+	assert.Equal(t, apiModels.CommandReadNdef.String(), nj.Steps[1].Command)
+	assert.Equal(t, apiModels.CommandLockPermanent.String(), nj.Steps[2].Command)
+}
+
+func TestRepositoryService_AddRestoreJob(t *testing.T) {
+	p := models.GenericJobParams{
+		AdapterId: "adapterId",
+		Repeat:    1,
+		Expire:    60,
+		Export:    true,
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+
+	_, nj, err := rep.AddRestoreJob(p, [][]byte{{0xA2, 0x04, 0x03, 0x00, 0xFE, 0x00}, {0xA2, 0x05, 0x00, 0x00, 0x00, 0x00}})
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Equal(t, "Restore tag", nj.JobName)
+	assert.Len(t, nj.Steps, 3)
+	assert.Equal(t, apiModels.CommandTransmitTag.String(), nj.Steps[0].Command)
+	assert.Equal(t, apiModels.TransmitTagParamsResource{TxBytes: "ogQDAP4A"}, nj.Steps[0].Params)
+	assert.Equal(t, apiModels.CommandGetDump.String(), nj.Steps[2].Command)
+}
+
 func TestRepositoryService_AddJobFromFile(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		assert.Equal(t, "/adapters/adapterId/jobs", req.URL.String())
@@ -406,3 +475,252 @@ func TestRepositoryService_AddJobFromFile(t *testing.T) {
 
 	assert.Equal(t, 3, amountOfRuns)
 }
+
+func TestRepositoryService_AddJobFromFile_rollback(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodDelete {
+			deleted = append(deleted, req.URL.String())
+			rw.WriteHeader(200)
+			return
+		}
+
+		var nj apiModels.NewJob
+		err := json.NewDecoder(req.Body).Decode(&nj)
+		assert.Nil(t, err)
+		// the second job is rejected
+		if nj.JobName == "Second job" {
+			rw.WriteHeader(400)
+			_, _ = rw.Write([]byte(`{"error_message": "Job is not valid", "error_info": "Repeat is too big"}`))
+			return
+		}
+
+		resp, err := json.Marshal(apiModels.JobResource{
+			JobID:     "first",
+			JobName:   nj.JobName,
+			AdapterID: "adapterId",
+			CreatedAt: "2006-01-02T15:04:05Z",
+			Status:    apiModels.JobStatusPending.String(),
+			Steps:     nj.Steps,
+		})
+		assert.Nil(t, err)
+		rw.WriteHeader(200)
+		_, _ = rw.Write(resp)
+	}))
+	defer server.Close()
+
+	nfc := client.New(strings.Replace(server.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	runs, err := rep.AddJobFromFile("adapterId", "reader_test_file.json", models.GenericJobParams{Expire: 60})
+	assert.EqualError(t, err, "Can't add job Second job from reader_test_file.json:2, 1 added jobs are deleted: "+
+		"Error in post job: Server responded with an error: Job is not valid (Repeat is too big)")
+	assert.Equal(t, 0, runs)
+	assert.Equal(t, []string{"/adapters/adapterId/jobs/first"}, deleted)
+}
+
+func TestRepositoryService_GetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var resp []byte
+		var err error
+
+		switch req.URL.String() {
+		case "/adapters/adapterId/tags?type=nfc":
+			resp, err = json.Marshal(apiModels.TagListResource{{
+				TagID:     "tagId",
+				Type:      apiModels.TagTypeNfc.String(),
+				AdapterID: "adapterId",
+				Uid:       "BKI7Eg==",
+			}})
+		case "/adapters/adapterId/tags/tagId":
+			resp, err = json.Marshal(apiModels.TagResource{
+				TagID:     "tagId",
+				Type:      apiModels.TagTypeNfc.String(),
+				AdapterID: "adapterId",
+				Uid:       "BKI7Eg==",
+				Atr:       "O48=",
+				Product:   "NTAG213",
+				Vendor:    "NXP",
+			})
+		default:
+			t.Errorf("Unexpected request: %s", req.URL.String())
+		}
+		if err != nil {
+			log.Fatal("Can't marshall test model\n", err)
+		}
+		rw.WriteHeader(200)
+		_, err = rw.Write(resp)
+		if err != nil {
+			log.Fatal("Can't return er\n", err)
+		}
+	}))
+
+	defer server.Close()
+
+	nfc := client.New(strings.Replace(server.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	tagType := apiModels.TagTypeNfc
+	tags, err := rep.GetTags("adapterId", &tagType, true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, tags, 1)
+	assert.Equal(t, "tagId", tags[0].TagID)
+	assert.Equal(t, []byte{0x04, 0xa2, 0x3b, 0x12}, tags[0].Uid)
+	assert.Equal(t, []byte{0x3b, 0x8f}, tags[0].Atr)
+	assert.Equal(t, "NTAG213", tags[0].Product)
+	assert.Equal(t, "NXP", tags[0].Vendor)
+
+	tag, err := rep.GetTag("adapterId", "tagId", true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Equal(t, tags[0], tag)
+}
+
+func TestRepositoryService_GetJobs(t *testing.T) {
+	jobResource := apiModels.JobResource{
+		JobID:       "jobId",
+		JobName:     "Job Name",
+		AdapterID:   "adapterId",
+		AdapterName: "adname",
+		CreatedAt:   "2006-01-02T15:04:05Z",
+		Status:      apiModels.JobStatusPending.String(),
+		Repeat:      3,
+		TotalRuns:   2,
+		SuccessRuns: 1,
+		ErrorRuns:   1,
+		Steps: []apiModels.JobStepResource{{
+			Command: apiModels.CommandTransmitTag.String(),
+			Params:  apiModels.TransmitTagParamsResource{TxBytes: "phJmug=="},
+		}},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var resp []byte
+		var err error
+
+		switch req.Method + " " + req.URL.String() {
+		case "GET /adapters/adapterId/jobs?status=pending&sortby=created_at&sortdir=desc&offset=1&limit=2":
+			resp, err = json.Marshal(apiModels.JobListResource{
+				Total:  3,
+				Length: 1,
+				Limit:  2,
+				Offset: 1,
+				Items:  []apiModels.JobResource{jobResource},
+			})
+		case "GET /adapters/adapterId/jobs/jobId":
+			resp, err = json.Marshal(jobResource)
+		case "PATCH /adapters/adapterId/jobs/jobId":
+			jr := jobResource
+			jr.Status = apiModels.JobStatusActive.String()
+			resp, err = json.Marshal(jr)
+		case "DELETE /adapters/adapterId/jobs/jobId":
+			resp = []byte("{}")
+		default:
+			t.Errorf("Unexpected request: %s %s", req.Method, req.URL.String())
+		}
+		if err != nil {
+			log.Fatal("Can't marshall test model\n", err)
+		}
+		rw.WriteHeader(200)
+		_, err = rw.Write(resp)
+		if err != nil {
+			log.Fatal("Can't return er\n", err)
+		}
+	}))
+
+	defer server.Close()
+
+	nfc := client.New(strings.Replace(server.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	status := apiModels.JobStatusPending
+	sortBy := "created_at"
+	sortDir := "desc"
+	limit := 2
+	offset := 1
+	jobs, pageInfo, err := rep.GetJobs("adapterId", client.JobFilter{
+		Status:  &status,
+		SortBy:  &sortBy,
+		SortDir: &sortDir,
+		Limit:   &limit,
+		Offset:  &offset,
+	}, true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, 3, pageInfo.Total)
+	assert.Equal(t, "jobId", jobs[0].JobID)
+	assert.Equal(t, 1, jobs[0].ErrorRuns)
+	assert.Equal(t, apiModels.CommandTransmitTag, jobs[0].Steps[0].Command)
+
+	job, err := rep.GetJob("adapterId", "jobId", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "a6 12 66 ba", job.Steps[0].Params.String())
+
+	job, err = rep.UpdateJobStatus("adapterId", "jobId", apiModels.JobStatusActive, true)
+	assert.Nil(t, err)
+	assert.Equal(t, apiModels.JobStatusActive, job.Status)
+
+	err = rep.DeleteJob("adapterId", "jobId")
+	assert.Nil(t, err)
+}
+
+func TestRepositoryService_GetEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/events?adapter_id=adapterId&name=tag_discovery&limit=1", req.URL.String())
+		resp, err := json.Marshal(apiModels.EventListResource{
+			Total:  5,
+			Length: 1,
+			Limit:  1,
+			Items: []apiModels.EventResource{{
+				EventID:     "eventId",
+				Name:        apiModels.EventNameTagDiscovery.String(),
+				AdapterID:   "adapterId",
+				AdapterName: "adname",
+				Data: apiModels.TagResource{
+					TagID: "tagId",
+					Type:  apiModels.TagTypeNfc.String(),
+					Uid:   "BKI7Eg==",
+				},
+				CreatedAt: "2006-01-02T15:04:05Z",
+			}},
+		})
+		if err != nil {
+			log.Fatal("Can't marshall test model\n", err)
+		}
+		rw.WriteHeader(200)
+		_, err = rw.Write(resp)
+		if err != nil {
+			log.Fatal("Can't return er\n", err)
+		}
+	}))
+
+	defer server.Close()
+
+	nfc := client.New(strings.Replace(server.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	adapterId := "adapterId"
+	name := apiModels.EventNameTagDiscovery
+	limit := 1
+	events, pageInfo, err := rep.GetEvents(&adapterId, client.EventFilter{Name: &name, Limit: &limit}, true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, events, 1)
+	assert.Equal(t, 5, pageInfo.Total)
+	assert.Equal(t, apiModels.EventNameTagDiscovery, events[0].Name)
+	assert.Equal(t, "tag tagId, type nfc, UID 04 a2 3b 12", getEventDetails(events[0]))
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
 	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 )
 
@@ -33,8 +37,242 @@ func (s *RepositoryService) printAdapters(adapters []apiModels.Adapter) {
 	fmt.Println("Adapters:")
 
 	for i, a := range adapters {
-		fmt.Printf("[%d] %s\n", i+1, a.Name)
+		fmt.Printf("[%d] %s (%s)\n", i+1, a.Name, a.AdapterID)
 	}
 
 	fmt.Println()
 }
+
+func (s *RepositoryService) printTags(tags []apiModels.Tag) {
+	if len(tags) == 0 {
+		fmt.Println("Tags not found")
+		return
+	}
+
+	fmt.Println("Tags:")
+
+	for i, t := range tags {
+		fmt.Printf("[%d] %s\n", i+1, t.TagID)
+		s.printTagDetails(t)
+	}
+
+	fmt.Println()
+}
+
+func (s *RepositoryService) printTag(t apiModels.Tag) {
+	fmt.Printf("Tag %s:\n", t.TagID)
+	s.printTagDetails(t)
+	fmt.Println()
+}
+
+func (s *RepositoryService) printTagDetails(t apiModels.Tag) {
+	fmt.Printf("   Type: %s\n", t.Type.String())
+	if len(t.Uid) > 0 {
+		fmt.Printf("   UID: % x\n", t.Uid)
+	}
+	if len(t.Atr) > 0 {
+		fmt.Printf("   ATR: % x\n", t.Atr)
+	}
+	if len(t.Product) > 0 {
+		fmt.Printf("   Product: %s\n", t.Product)
+	}
+	if len(t.Vendor) > 0 {
+		fmt.Printf("   Vendor: %s\n", t.Vendor)
+	}
+}
+
+func (s *RepositoryService) printJobs(jobs []apiModels.Job, p apiModels.PageInfo) {
+	if len(jobs) == 0 {
+		fmt.Println("Jobs not found")
+		return
+	}
+
+	fmt.Println("Jobs:")
+
+	for i, j := range jobs {
+		fmt.Printf("[%d] %s (%s)\n", p.Offset+i+1, j.JobName, j.JobID)
+		s.printJobCounters(j)
+	}
+
+	fmt.Printf("Shown %d of %d jobs\n", len(jobs), p.Total)
+	fmt.Println()
+}
+
+func (s *RepositoryService) printJob(j apiModels.Job) {
+	fmt.Printf("Job %s (%s):\n", j.JobName, j.JobID)
+	fmt.Printf("   Adapter: %s\n", j.AdapterName)
+	s.printJobCounters(j)
+	fmt.Printf("   Expire after: %d sec\n", j.ExpireAfter)
+	fmt.Printf("   Created at: %s\n", j.CreatedAt.Format(time.RFC3339))
+
+	if len(j.Steps) > 0 {
+		fmt.Println("   Steps:")
+	}
+	for i, step := range j.Steps {
+		fmt.Printf("   [Step %d] %s\n", i+1, MapRunStepCmdToString[step.Command])
+
+		if step.Params != nil {
+			pStr := step.Params.String()
+			if len(pStr) > 0 {
+				fmt.Printf("   Params:\n%s\n", pStr)
+			}
+		}
+	}
+
+	fmt.Println()
+}
+
+func (s *RepositoryService) printJobCounters(j apiModels.Job) {
+	fmt.Printf("   Status: %s\n", j.Status.String())
+	fmt.Printf("   Runs: total %d (%d success, %d failed). Repeat %d, remain %d runs\n", j.TotalRuns, j.SuccessRuns, j.ErrorRuns, j.Repeat, j.Repeat-j.SuccessRuns)
+}
+
+func (s *RepositoryService) printRuns(runs []apiModels.JobRun, p apiModels.PageInfo) {
+	if len(runs) == 0 {
+		fmt.Println("Runs not found")
+		return
+	}
+
+	fmt.Println("Runs:")
+
+	for i, r := range runs {
+		status := r.Status.String()
+		if r.Status == apiModels.JobRunStatusSuccess {
+			status = color.GreenString(status)
+		} else if r.Status == apiModels.JobRunStatusError {
+			status = color.RedString(status)
+		}
+
+		fmt.Printf("[%d] %s %s – %s\n", p.Offset+i+1, r.CreatedAt.Format(time.RFC3339), r.RunID, status)
+		fmt.Printf("   Job: %s (%s)\n", r.JobName, r.JobID)
+		if len(r.Tag.Uid) > 0 {
+			fmt.Printf("   Tag UID: % x\n", r.Tag.Uid)
+		}
+	}
+
+	fmt.Printf("Shown %d of %d runs\n", len(runs), p.Total)
+	fmt.Println()
+}
+
+func (s *RepositoryService) printRun(r apiModels.JobRun) {
+	fmt.Printf("Run %s:\n", r.RunID)
+	fmt.Printf("   Job: %s (%s)\n", r.JobName, r.JobID)
+	fmt.Printf("   Adapter: %s\n", r.AdapterName)
+	fmt.Printf("   Status: %s\n", r.Status.String())
+	fmt.Printf("   Created at: %s\n", r.CreatedAt.Format(time.RFC3339))
+	fmt.Printf("Tag %s:\n", r.Tag.TagID)
+	s.printTagDetails(r.Tag)
+
+	s.printRunResults(r)
+	fmt.Println()
+}
+
+func (s *RepositoryService) printRunResults(jobRun apiModels.JobRun) {
+	prefix := s.adapterPrefix(jobRun.AdapterName)
+	fmt.Printf("%sJob %s: -----run results start-----\n", prefix, jobRun.JobName)
+
+	for i, s := range jobRun.Results {
+		endStr := ""
+		if len(s.Message) > 0 {
+			endStr = "(" + s.Message + ")"
+		}
+
+		if s.Status == apiModels.CommandStatusSuccess {
+			color.Green("%s[Step %d] %s – %s %s", prefix, i+1, MapRunStepCmdToString[s.Command], s.Status.String(), endStr)
+		} else {
+			color.Red("%s[Step %d] %s – %s %s", prefix, i+1, MapRunStepCmdToString[s.Command], s.Status.String(), endStr)
+		}
+
+		if s.Params != nil {
+			pStr := s.Params.String()
+			if len(pStr) > 0 {
+				fmt.Printf("Params:\n%s\n", pStr)
+			}
+		}
+
+		if s.Output != nil {
+			oStr := s.Output.String()
+			if len(oStr) > 0 {
+				if strings.Contains(oStr, "Record") {
+					if strings.Contains(oStr, "Empty") {
+						oStr = color.CyanString(oStr)
+					} else {
+						oStr = color.MagentaString(oStr)
+					}
+				}
+				fmt.Printf("Output:\n%s\n", oStr)
+
+			}
+		}
+	}
+
+	fmt.Printf("%sJob %s: -----run results end-----\n", prefix, jobRun.JobName)
+}
+
+func (s *RepositoryService) printEvents(events []apiModels.Event, p apiModels.PageInfo) {
+	if len(events) == 0 {
+		fmt.Println("Events not found")
+		return
+	}
+
+	fmt.Println("Events:")
+
+	for _, e := range events {
+		s.printEvent(e)
+	}
+
+	fmt.Printf("Shown %d of %d events\n", len(events), p.Total)
+	fmt.Println()
+}
+
+func (s *RepositoryService) printEvent(e apiModels.Event) {
+	str := fmt.Sprintf("%s %s", e.CreatedAt.Format(time.RFC3339), e.Name.String())
+	if len(e.AdapterName) > 0 {
+		str += fmt.Sprintf(" (%s)", e.AdapterName)
+	}
+
+	details := getEventDetails(e)
+	if len(details) > 0 {
+		str += ": " + details
+	}
+
+	switch e.Name {
+	case apiModels.EventNameRunSuccess:
+		color.Green(str)
+	case apiModels.EventNameRunError:
+		color.Red(str)
+	default:
+		fmt.Println(str)
+	}
+}
+
+func getEventDetails(e apiModels.Event) string {
+	switch e.Name {
+	case apiModels.EventNameTagDiscovery, apiModels.EventNameTagRelease:
+		tr, ok := e.GetTag()
+		if !ok {
+			return ""
+		}
+		t, err := tr.ToTag()
+		if err != nil {
+			return fmt.Sprintf("tag %s", tr.TagID)
+		}
+		return fmt.Sprintf("tag %s, type %s, UID % x", t.TagID, t.Type.String(), t.Uid)
+	case apiModels.EventNameAdapterDiscovery, apiModels.EventNameAdapterRelease:
+		a, ok := e.GetAdapter()
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("adapter %s", a.Name)
+	case apiModels.EventNameJobSubmited, apiModels.EventNameJobActivated, apiModels.EventNameJobPended,
+		apiModels.EventNameJobDeleted, apiModels.EventNameJobFinished,
+		apiModels.EventNameRunStarted, apiModels.EventNameRunSuccess, apiModels.EventNameRunError:
+		j, ok := e.GetJob()
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("job %s", j.JobName)
+	}
+
+	return ""
+}
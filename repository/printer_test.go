@@ -3,6 +3,7 @@ package repository
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/taglme/nfc-goclient/pkg/client"
 	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 )
@@ -37,3 +38,117 @@ func TestApiService_printAppInfo(t *testing.T) {
 	rep := New(&nfc)
 	rep.printAppInfo(appInfo)
 }
+
+func TestApiService_printTags(t *testing.T) {
+	tags := []apiModels.Tag{
+		{
+			TagID:   "Tag ID 1",
+			Type:    apiModels.TagTypeNfc,
+			Uid:     []byte{0x04, 0xa2, 0x3b, 0x12},
+			Atr:     []byte{0x3b, 0x8f},
+			Product: "NTAG213",
+			Vendor:  "NXP",
+		},
+		{
+			TagID: "Tag ID 2",
+			Type:  apiModels.TagTypeBarcode,
+		},
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+	rep.printTags(tags)
+	rep.printTags(nil)
+	rep.printTag(tags[0])
+}
+
+func TestApiService_printJobs(t *testing.T) {
+	jobs := []apiModels.Job{
+		{
+			JobID:       "Job ID 1",
+			JobName:     "Job 1",
+			Status:      apiModels.JobStatusActive,
+			Repeat:      3,
+			TotalRuns:   2,
+			SuccessRuns: 1,
+			ErrorRuns:   1,
+			Steps: []apiModels.JobStep{
+				{
+					Command: apiModels.CommandGetTags,
+					Params:  apiModels.GetTagsParams{},
+				},
+				{
+					Command: apiModels.CommandTransmitTag,
+					Params:  apiModels.TransmitTagParams{TxBytes: []byte{0x30, 0x00}},
+				},
+			},
+		},
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+	rep.printJobs(jobs, apiModels.PageInfo{Total: 1, Length: 1})
+	rep.printJobs(nil, apiModels.PageInfo{})
+	rep.printJob(jobs[0])
+}
+
+func TestApiService_printRuns(t *testing.T) {
+	runs := []apiModels.JobRun{
+		{
+			RunID:   "Run ID 1",
+			JobID:   "Job ID 1",
+			JobName: "Job 1",
+			Status:  apiModels.JobRunStatusError,
+			Tag: apiModels.Tag{
+				TagID: "Tag ID 1",
+				Type:  apiModels.TagTypeNfc,
+				Uid:   []byte{0x04, 0xa2, 0x3b, 0x12},
+			},
+			Results: []apiModels.StepResult{
+				{
+					Command: apiModels.CommandTransmitTag,
+					Params:  apiModels.TransmitTagParams{TxBytes: []byte{0x30, 0x00}},
+					Output:  apiModels.TransmitTagOutput{RxBytes: []byte{0x04, 0xa2}},
+					Status:  apiModels.CommandStatusError,
+					Message: "Timeout",
+				},
+			},
+		},
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+	rep.printRuns(runs, apiModels.PageInfo{Total: 1, Length: 1})
+	rep.printRuns(nil, apiModels.PageInfo{})
+	rep.printRun(runs[0])
+}
+
+func TestApiService_printEvents(t *testing.T) {
+	events := []apiModels.Event{
+		{
+			Name:        apiModels.EventNameAdapterDiscovery,
+			AdapterName: "Adapter 1",
+			Data:        map[string]interface{}{"name": "Adapter 1"},
+		},
+		{
+			Name: apiModels.EventNameRunSuccess,
+			Data: map[string]interface{}{"job_name": "Job 1"},
+		},
+		{
+			Name: apiModels.EventNameRunError,
+			Data: map[string]interface{}{"job_name": "Job 1"},
+		},
+		{
+			Name: apiModels.EventNameServerStarted,
+		},
+	}
+
+	nfc := client.New("url")
+	rep := New(&nfc)
+	rep.printEvents(events, apiModels.PageInfo{Total: 4, Length: 4})
+	rep.printEvents(nil, apiModels.PageInfo{})
+
+	assert.Equal(t, "adapter Adapter 1", getEventDetails(events[0]))
+	assert.Equal(t, "job Job 1", getEventDetails(events[1]))
+	assert.Equal(t, "", getEventDetails(events[3]))
+}
@@ -1,30 +1,10 @@
 package repository
 
 import (
-	"bufio"
-	"encoding/json"
-	"github.com/pkg/errors"
-	apiModels "github.com/taglme/nfc-goclient/pkg/models"
-	"os"
+	"github.com/taglme/nfc-cli/jobfile"
 )
 
-func (s *RepositoryService) readFromFile(filename string) (data []apiModels.NewJob, err error) {
-	file, err := os.OpenFile(filename, os.O_RDONLY, os.ModePerm)
-	if err != nil {
-		return nil, errors.Wrap(err, "Can't open the file: ")
-	}
-
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		nj := apiModels.NewJob{}
-		err := json.Unmarshal([]byte(scanner.Text()), &nj)
-		if err != nil {
-			return data, errors.Wrap(err, "Can't unmarshall the data on reading from the file")
-		}
-		data = append(data, nj)
-	}
-
-	return data, err
+// readFromFile reads job file or JSON lines of new job resources
+func (s *RepositoryService) readFromFile(filename string, vars map[string]string) ([]jobfile.Entry, error) {
+	return jobfile.Load(filename, vars)
 }
@@ -12,12 +12,26 @@ func TestRepositoryService_readFromFile(t *testing.T) {
 	nfc := client.New("url")
 	rep := New(&nfc)
 
-	data, err := rep.readFromFile("reader_test_file.json")
+	data, err := rep.readFromFile("reader_test_file.json", nil)
 	if err != nil {
 		t.Error(err)
 		log.Fatal(err)
 	}
 
-	assert.Equal(t, "Write tag", data[0].JobName)
-	assert.Equal(t, "Second job", data[1].JobName)
+	assert.Equal(t, "Write tag", data[0].Job.JobName)
+	assert.Equal(t, "Second job", data[1].Job.JobName)
+}
+
+func TestRepositoryService_readFromFile_jobFile(t *testing.T) {
+	nfc := client.New("url")
+	rep := New(&nfc)
+
+	data, err := rep.readFromFile("reader_test_file.yaml", map[string]string{"URL": "https://tagl.me"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(data))
+	assert.Equal(t, "Write tag", data[0].Job.JobName)
+	assert.Equal(t, 1, data[0].Job.Repeat)
+	assert.Equal(t, "Transmit tag", data[1].Job.JobName)
+	assert.Equal(t, 9, data[1].Line)
+	assert.Equal(t, "transmit_tag", data[1].Job.Steps[0].Command)
 }
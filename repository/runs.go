@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/f2prateek/train"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+)
+
+// nfc-goclient RunService can't unmarshal run step results as their params and output are interfaces,
+// so runs are requested directly and parsed the same way as runs received with WS events.
+
+type runListResource struct {
+	Total  int
+	Length int
+	Limit  int
+	Offset int
+	Items  []interface{}
+}
+
+func (s *RepositoryService) SetHost(host string, interceptors ...train.Interceptor) {
+	s.url = "http://" + host
+	s.httpClient = &http.Client{
+		Transport: train.Transport(interceptors...),
+	}
+}
+
+func (s *RepositoryService) GetRuns(adapterId string, filter client.RunFilter, withOutput bool) ([]apiModels.JobRun, apiModels.PageInfo, error) {
+	var runs []apiModels.JobRun
+	var pageInfo apiModels.PageInfo
+
+	// without limit all pages are requested
+	fetchAll := filter.Limit == nil
+	offset := 0
+	if filter.Offset != nil {
+		offset = *filter.Offset
+	}
+	pageInfo.Offset = offset
+
+	for {
+		filter.Offset = &offset
+
+		var list runListResource
+		err := s.getResource("/adapters/"+adapterId+"/runs"+buildRunsQuery(filter), &list)
+		if err != nil {
+			return runs, pageInfo, errors.Wrap(err, "Can't get runs")
+		}
+
+		for _, item := range list.Items {
+			if _, ok := item.(map[string]interface{}); !ok {
+				return runs, pageInfo, errors.New("Can't parse run from the runs list")
+			}
+			runs = append(runs, parseJobRunStruct(item))
+		}
+
+		pageInfo.Total = list.Total
+		pageInfo.Length = len(runs)
+		pageInfo.Limit = list.Limit
+
+		offset += len(list.Items)
+		if !fetchAll || len(list.Items) == 0 || offset >= list.Total {
+			break
+		}
+	}
+
+	if withOutput {
+		s.printRuns(runs, pageInfo)
+	}
+
+	return runs, pageInfo, nil
+}
+
+func (s *RepositoryService) GetRun(adapterId, runId string, withOutput bool) (apiModels.JobRun, error) {
+	var data map[string]interface{}
+	err := s.getResource("/adapters/"+adapterId+"/runs/"+runId, &data)
+	if err != nil {
+		return apiModels.JobRun{}, errors.Wrap(err, "Can't get run")
+	}
+
+	r := parseJobRunStruct(data)
+	if withOutput {
+		s.printRun(r)
+	}
+
+	return r, nil
+}
+
+func (s *RepositoryService) getResource(path string, res interface{}) error {
+	if s.httpClient == nil {
+		return errors.New("Server host is not set")
+	}
+
+	resp, err := s.httpClient.Get(s.url + path)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "Can't read response body")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse apiModels.ErrorResponse
+		err = json.Unmarshal(body, &errorResponse)
+		if err != nil {
+			return errors.Errorf("Server responded with status %d", resp.StatusCode)
+		}
+		return errors.Errorf("Server responded with an error: %s (%s)", errorResponse.Message, errorResponse.Info)
+	}
+
+	err = json.Unmarshal(body, res)
+	if err != nil {
+		return errors.Wrap(err, "Can't unmarshal response")
+	}
+
+	return nil
+}
+
+func buildRunsQuery(filter client.RunFilter) string {
+	q := url.Values{}
+
+	if filter.Status != nil {
+		q.Set("status", filter.Status.String())
+	}
+	if filter.JobID != nil {
+		q.Set("job_id", *filter.JobID)
+	}
+	if filter.SortBy != nil {
+		q.Set("sortby", *filter.SortBy)
+	}
+	if filter.SortDir != nil {
+		q.Set("sortdir", *filter.SortDir)
+	}
+	if filter.Offset != nil {
+		q.Set("offset", strconv.Itoa(*filter.Offset))
+	}
+	if filter.Limit != nil {
+		q.Set("limit", strconv.Itoa(*filter.Limit))
+	}
+
+	if len(q) == 0 {
+		return ""
+	}
+
+	return "?" + q.Encode()
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+)
+
+func testRunResource(runId string) map[string]interface{} {
+	return map[string]interface{}{
+		"run_id":       runId,
+		"job_id":       "jobId",
+		"job_name":     "Job Name",
+		"status":       apiModels.JobRunStatusSuccess.String(),
+		"adapter_id":   "adapterId",
+		"adapter_name": "Adapter Name",
+		"created_at":   "2020-03-19T16:10:33.580Z",
+		"tag": map[string]interface{}{
+			"tag_id": "tagId",
+			"type":   apiModels.TagTypeNfc.String(),
+			"uid":    "qhIyag==",
+			"atr":    "qhIyag==",
+		},
+		"results": []interface{}{
+			map[string]interface{}{
+				"status":  apiModels.CommandStatusSuccess.String(),
+				"command": apiModels.CommandTransmitTag.String(),
+				"params":  map[string]interface{}{"tx_bytes": "MAA="},
+				"output":  map[string]interface{}{"rx_bytes": "qhIyag=="},
+			},
+		},
+	}
+}
+
+func TestRepositoryService_GetRuns(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		var resp interface{}
+
+		switch req.URL.String() {
+		case "/adapters/adapterId/runs?job_id=jobId&offset=0":
+			resp = map[string]interface{}{
+				"total": 3, "length": 2, "limit": 2, "offset": 0,
+				"items": []interface{}{testRunResource("run1"), testRunResource("run2")},
+			}
+		case "/adapters/adapterId/runs?job_id=jobId&offset=2":
+			resp = map[string]interface{}{
+				"total": 3, "length": 1, "limit": 2, "offset": 2,
+				"items": []interface{}{testRunResource("run3")},
+			}
+		case "/adapters/adapterId/runs?limit=1&offset=1&status=success":
+			resp = map[string]interface{}{
+				"total": 3, "length": 1, "limit": 1, "offset": 1,
+				"items": []interface{}{testRunResource("run2")},
+			}
+		case "/adapters/adapterId/runs/run1":
+			resp = testRunResource("run1")
+		default:
+			rw.WriteHeader(404)
+			resp = apiModels.ErrorResponse{Message: "Not found", Info: req.URL.String()}
+		}
+
+		b, err := json.Marshal(resp)
+		if err != nil {
+			log.Fatal("Can't marshall test model\n", err)
+		}
+		_, err = rw.Write(b)
+		if err != nil {
+			log.Fatal("Can't return er\n", err)
+		}
+	}))
+
+	defer server.Close()
+
+	host := strings.Replace(server.URL, "http://", "", -1)
+	nfc := client.New(host)
+	rep := New(&nfc)
+
+	_, _, err := rep.GetRuns("adapterId", client.RunFilter{}, false)
+	assert.EqualError(t, err, "Can't get runs: Server host is not set")
+
+	rep.SetHost(host)
+
+	jobId := "jobId"
+	runs, pageInfo, err := rep.GetRuns("adapterId", client.RunFilter{JobID: &jobId}, true)
+	if err != nil {
+		t.Error(err)
+		log.Fatal(err)
+	}
+
+	assert.Len(t, runs, 3)
+	assert.Equal(t, 3, pageInfo.Total)
+	assert.Equal(t, 3, pageInfo.Length)
+	assert.Equal(t, "run3", runs[2].RunID)
+	assert.Equal(t, apiModels.JobRunStatusSuccess, runs[0].Status)
+	assert.Equal(t, []byte{0xaa, 0x12, 0x32, 0x6a}, runs[0].Tag.Uid)
+	assert.Equal(t, apiModels.CommandTransmitTag, runs[0].Results[0].Command)
+	assert.Equal(t, "aa 12 32 6a", runs[0].Results[0].Output.String())
+
+	status := apiModels.JobRunStatusSuccess
+	limit := 1
+	offset := 1
+	runs, pageInfo, err = rep.GetRuns("adapterId", client.RunFilter{Status: &status, Limit: &limit, Offset: &offset}, true)
+	assert.Nil(t, err)
+	assert.Len(t, runs, 1)
+	assert.Equal(t, 1, pageInfo.Offset)
+	assert.Equal(t, "run2", runs[0].RunID)
+
+	run, err := rep.GetRun("adapterId", "run1", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "run1", run.RunID)
+	assert.Equal(t, "Job Name", run.JobName)
+
+	_, err = rep.GetRun("adapterId", "unknown", false)
+	assert.EqualError(t, err, "Can't get run: Server responded with an error: Not found (/adapters/adapterId/runs/unknown)")
+}
@@ -3,7 +3,7 @@ package repository
 import (
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/fatih/color"
 
@@ -11,30 +11,125 @@ import (
 	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 )
 
-func (s *RepositoryService) RunWsConnection(eHandler func(models.Event, interface{}), errHandler func(error)) error {
-	s.client.Ws.OnEvent(func(event apiModels.Event) {
+// reconnection delay is doubled after every failed attempt up to max delay
+var (
+	wsReconnectDelay    = 500 * time.Millisecond
+	wsReconnectMaxDelay = 30 * time.Second
+	wsReconnectAttempts = 10
+)
+
+// RunWsConnection connects to the server events. Lost connection is restored with exponential backoff,
+// reconnectHandler is called after connection is restored and errHandler when it can't be restored
+func (s *RepositoryService) RunWsConnection(eHandler func(models.Event, interface{}), errHandler func(error), reconnectHandler func()) error {
+	stop := make(chan struct{})
+	s.wsStop = stop
+
+	s.ws.OnEvent(func(event apiModels.Event) {
 		s.eventHandler(event)
 		eHandler(MapApiEventNameToCliEvent[event.Name], event.Data)
 	})
 
-	s.client.Ws.OnError(func(err error) {
+	s.ws.OnError(func(err error) {
+		go s.reconnectWs(err, stop, errHandler, reconnectHandler)
+	})
+
+	return s.ws.Connect()
+}
+
+func (s *RepositoryService) FollowEvents(adapterId *string, name *apiModels.EventName, withOutput bool, eHandler func(apiModels.Event), errHandler func(error)) error {
+	s.ws.OnEvent(func(event apiModels.Event) {
+		if adapterId != nil && event.AdapterID != *adapterId {
+			return
+		}
+		if name != nil && event.Name != *name {
+			return
+		}
+
+		if withOutput {
+			s.printEvent(event)
+		}
+		eHandler(event)
+	})
+
+	s.ws.OnError(func(err error) {
 		errHandler(err)
 	})
 
-	err := s.client.Ws.Connect()
-	if err != nil || !s.client.Ws.IsConnected() {
-		return err
+	return s.ws.Connect()
+}
+
+// SetAdapterPrefix enables adapter name prefix of event output lines when jobs run on several adapters
+func (s *RepositoryService) SetAdapterPrefix(enabled bool) {
+	s.prefixAdapter = enabled
+}
+
+func (s *RepositoryService) adapterPrefix(adapterName string) string {
+	if !s.prefixAdapter {
+		return ""
+	}
+
+	return "[" + adapterName + "] "
+}
+
+// reconnectWs connects to the server again with delay doubled after every failed attempt. Connection isn't restored
+// when it is stopped
+func (s *RepositoryService) reconnectWs(cause error, stop chan struct{}, errHandler func(error), reconnectHandler func()) {
+	// errors of message parsing don't close the connection
+	if _, ok := cause.(*wsClosedError); !ok {
+		log.Println("Can't process server event: ", cause)
+		return
+	}
+
+	delay := wsReconnectDelay
+	for attempt := 1; attempt <= wsReconnectAttempts; attempt++ {
+		if wsStopped(stop) {
+			return
+		}
+		fmt.Printf("Server connection lost. Reconnecting in %s (attempt %d of %d)...\n", delay, attempt, wsReconnectAttempts)
+		select {
+		case <-stop:
+			return
+		case <-time.After(delay):
+		}
+
+		err := s.ws.Connect()
+		if err == nil && wsStopped(stop) {
+			// the connection is stopped while connecting
+			_ = s.ws.Disconnect()
+			return
+		}
+		if err == nil {
+			fmt.Println("Server connection restored")
+			reconnectHandler()
+			return
+		}
+		cause = err
+
+		delay *= 2
+		if delay > wsReconnectMaxDelay {
+			delay = wsReconnectMaxDelay
+		}
 	}
 
-	return nil
+	errHandler(cause)
+}
+
+func wsStopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
 }
 
 func (s *RepositoryService) StopWsConnection() error {
-	if s.client.Ws.IsConnected() {
-		return s.client.Ws.Disconnect()
+	if s.wsStop != nil {
+		close(s.wsStop)
+		s.wsStop = nil
 	}
 
-	return nil
+	return s.ws.Disconnect()
 }
 
 func (s *RepositoryService) eventHandler(e apiModels.Event) {
@@ -45,21 +140,21 @@ func (s *RepositoryService) eventHandler(e apiModels.Event) {
 			log.Println("Can't get Job from Event.")
 			return
 		}
-		fmt.Printf("Job %s: submitted to adapter %s\n", j.JobName, j.AdapterName)
+		fmt.Printf("%sJob %s: submitted to adapter %s\n", s.adapterPrefix(j.AdapterName), j.JobName, j.AdapterName)
 	case apiModels.EventNameJobActivated:
 		j, ok := e.GetJob()
 		if !ok {
 			log.Println("Can't get Job from Event.")
 			return
 		}
-		fmt.Printf("Job %s: activated. Waiting for NFC tag...\n", j.JobName)
+		fmt.Printf("%sJob %s: activated. Waiting for NFC tag...\n", s.adapterPrefix(j.AdapterName), j.JobName)
 	case apiModels.EventNameRunStarted:
 		j, ok := e.GetJob()
 		if !ok {
 			log.Println("Can't get Job from Event.")
 			return
 		}
-		fmt.Printf("Job %s: execution started. Hold NFC tag steady...\n", j.JobName)
+		fmt.Printf("%sJob %s: execution started. Hold NFC tag steady...\n", s.adapterPrefix(j.AdapterName), j.JobName)
 	case apiModels.EventNameRunSuccess, apiModels.EventNameRunError:
 		j, ok := e.GetJob()
 		if !ok {
@@ -68,58 +163,21 @@ func (s *RepositoryService) eventHandler(e apiModels.Event) {
 		}
 
 		if e.Name.String() == "run_success" {
-			color.Green("Job %s: run finished successfully.\n", j.JobName)
+			color.Green("%sJob %s: run finished successfully.\n", s.adapterPrefix(j.AdapterName), j.JobName)
 		} else {
-			color.Red("Job %s: run finished unsuccessfully.\n", j.JobName)
+			color.Red("%sJob %s: run finished unsuccessfully.\n", s.adapterPrefix(j.AdapterName), j.JobName)
 		}
 
 		jobRun := parseJobRunStruct(e.Data)
-		fmt.Printf("Job %s: -----run results start-----\n", j.JobName)
-
-		for i, s := range jobRun.Results {
-			endStr := ""
-			if len(s.Message) > 0 {
-				endStr = "(" + s.Message + ")"
-			}
-
-			if s.Status == apiModels.CommandStatusSuccess {
-				color.Green("[Step %d] %s – %s %s", i+1, MapRunStepCmdToString[s.Command], s.Status.String(), endStr)
-			} else {
-				color.Red("[Step %d] %s – %s %s", i+1, MapRunStepCmdToString[s.Command], s.Status.String(), endStr)
-			}
-
-			if s.Params != nil {
-				pStr := s.Params.String()
-				if len(pStr) > 0 {
-					fmt.Printf("Params:\n%s\n", pStr)
-				}
-			}
-
-			if s.Output != nil {
-				oStr := s.Output.String()
-				if len(oStr) > 0 {
-					if strings.Contains(oStr, "Record") {
-						if strings.Contains(oStr, "Empty") {
-							oStr = color.CyanString(oStr)
-						} else {
-							oStr = color.MagentaString(oStr)
-						}
-					}
-					fmt.Printf("Output:\n%s\n", oStr)
-
-				}
-			}
-		}
-
-		fmt.Printf("Job %s: -----run results end-----\n", j.JobName)
+		s.printRunResults(jobRun)
 
-		job, err := s.GetJob(j.AdapterID, j.JobID)
+		job, err := s.GetJob(j.AdapterID, j.JobID, false)
 		if err == nil {
 			// we are not handling this error as job simply can be deleted at this point so request will always fail at last iteration
-			fmt.Printf("Job %s: total %d runs (%d success, %d failed). Remain %d runs\n", job.JobName, job.TotalRuns, job.SuccessRuns, job.ErrorRuns, job.Repeat-job.SuccessRuns)
+			fmt.Printf("%sJob %s: total %d runs (%d success, %d failed). Remain %d runs\n", s.adapterPrefix(j.AdapterName), job.JobName, job.TotalRuns, job.SuccessRuns, job.ErrorRuns, job.Repeat-job.SuccessRuns)
 
 			if job.Repeat-job.SuccessRuns > 0 {
-				fmt.Printf("Job %s: waiting for NFC tag...\n", j.JobName)
+				fmt.Printf("%sJob %s: waiting for NFC tag...\n", s.adapterPrefix(j.AdapterName), j.JobName)
 			}
 		}
 	case apiModels.EventNameJobFinished:
@@ -129,10 +187,10 @@ func (s *RepositoryService) eventHandler(e apiModels.Event) {
 			return
 		}
 
-		fmt.Printf("Job %s: total %d runs (%d success, %d failed). Remain %d runs\n", job.JobName, job.TotalRuns, job.SuccessRuns, job.ErrorRuns, job.Repeat-job.SuccessRuns)
-		fmt.Printf("Job %s: finished successfully by adapter %s\n", job.JobName, job.AdapterName)
+		fmt.Printf("%sJob %s: total %d runs (%d success, %d failed). Remain %d runs\n", s.adapterPrefix(job.AdapterName), job.JobName, job.TotalRuns, job.SuccessRuns, job.ErrorRuns, job.Repeat-job.SuccessRuns)
+		fmt.Printf("%sJob %s: finished successfully by adapter %s\n", s.adapterPrefix(job.AdapterName), job.JobName, job.AdapterName)
 	case apiModels.EventNameJobDeleted:
-		fmt.Println("Job has been deleted")
+		fmt.Printf("%sJob has been deleted\n", s.adapterPrefix(e.AdapterName))
 	}
 }
 
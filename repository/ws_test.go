@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -61,13 +62,98 @@ func TestRepositoryService_RunWsConnection_StopWSConnection(t *testing.T) {
 	nfc := client.New(strings.Replace(s.URL, "http://", "", -1))
 	rep := New(&nfc)
 
-	assert.Equal(t, false, rep.client.Ws.IsConnected())
-	err := rep.RunWsConnection(eventHandler, errHandler)
+	assert.Equal(t, false, rep.ws.IsConnected())
+	err := rep.RunWsConnection(eventHandler, errHandler, func() {})
 	assert.Nil(t, err)
-	assert.Equal(t, true, rep.client.Ws.IsConnected())
+	assert.Equal(t, true, rep.ws.IsConnected())
+	err = rep.StopWsConnection()
+	assert.Nil(t, err)
+	assert.Equal(t, false, rep.ws.IsConnected())
+}
+
+func TestRepositoryService_RunWsConnection_reconnect(t *testing.T) {
+	var connections int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first connection is closed by the server
+		if atomic.AddInt32(&connections, 1) == 1 {
+			c, err := upgrader.Upgrade(w, r, nil)
+			if err == nil {
+				c.Close()
+			}
+			return
+		}
+		echo(w, r)
+	}))
+	defer s.Close()
+
+	delay := wsReconnectDelay
+	wsReconnectDelay = 10 * time.Millisecond
+	defer func() { wsReconnectDelay = delay }()
+
+	nfc := client.New(strings.Replace(s.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	reconnected := make(chan struct{}, 1)
+	err := rep.RunWsConnection(func(models.Event, interface{}) {}, func(err error) {
+		t.Error("Connection isn't restored: ", err)
+	}, func() {
+		reconnected <- struct{}{}
+	})
+	assert.Nil(t, err)
+
+	select {
+	case <-reconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection isn't restored")
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&connections))
+	err = rep.StopWsConnection()
+	assert.Nil(t, err)
+}
+
+func TestRepositoryService_StopWsConnection_reconnect(t *testing.T) {
+	var connections int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&connections, 1)
+		echo(w, r)
+	}))
+	defer s.Close()
+
+	delay := wsReconnectDelay
+	wsReconnectDelay = 10 * time.Millisecond
+	defer func() { wsReconnectDelay = delay }()
+
+	nfc := client.New(strings.Replace(s.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	err := rep.RunWsConnection(func(models.Event, interface{}) {}, func(err error) {
+		t.Error("Stopped connection is reported as lost: ", err)
+	}, func() {
+		t.Error("Stopped connection is restored")
+	})
+	assert.Nil(t, err)
+	err = rep.StopWsConnection()
+	assert.Nil(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&connections))
+	assert.Equal(t, false, rep.ws.IsConnected())
+}
+
+func TestRepositoryService_FollowEvents(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(echo))
+	defer s.Close()
+
+	nfc := client.New(strings.Replace(s.URL, "http://", "", -1))
+	rep := New(&nfc)
+
+	adapterId := "123"
+	name := apiModels.EventNameAdapterDiscovery
+	err := rep.FollowEvents(&adapterId, &name, true, func(apiModels.Event) {}, errHandler)
+	assert.Nil(t, err)
+	assert.Equal(t, true, rep.ws.IsConnected())
 	err = rep.StopWsConnection()
 	assert.Nil(t, err)
-	assert.Equal(t, false, rep.client.Ws.IsConnected())
 }
 
 func TestRepositoryService_eventHandler(t *testing.T) {
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+)
+
+// wsClosedError is passed to error handlers when the connection is closed by the server or lost
+type wsClosedError struct {
+	err error
+}
+
+func (e *wsClosedError) Error() string {
+	return "Can't read WS message: " + e.err.Error()
+}
+
+// wsService is the client.WsService which connection is guarded by mutex, so it can be connected and disconnected
+// while events are read. Error handlers aren't called when the connection is closed by Disconnect
+type wsService struct {
+	url           string
+	mutex         sync.Mutex
+	conn          *websocket.Conn
+	handlers      []client.EventHandler
+	errorHandlers []client.ErrorHandler
+}
+
+func newWsService(url string) *wsService {
+	return &wsService{url: url}
+}
+
+func (s *wsService) Connect() error {
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "Can't connect to the ws endpoint")
+	}
+
+	s.mutex.Lock()
+	s.conn = conn
+	s.mutex.Unlock()
+	go s.read(conn)
+
+	return nil
+}
+
+func (s *wsService) IsConnected() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.conn != nil
+}
+
+func (s *wsService) Disconnect() error {
+	s.mutex.Lock()
+	conn := s.conn
+	s.conn = nil
+	s.mutex.Unlock()
+	if conn == nil {
+		return nil
+	}
+	defer conn.Close()
+
+	err := conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "WS connection closed"))
+	if err != nil {
+		return errors.Wrap(err, "Error on close WS connection")
+	}
+
+	return nil
+}
+
+func (s *wsService) SetLocale(locale string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.conn == nil {
+		return errors.New("Can't set locale. Connection were not initialized")
+	}
+
+	loc, ok := apiModels.StringToLocale(locale)
+	if !ok {
+		loc = apiModels.LocaleEn
+	}
+	jobStep := apiModels.JobStep{
+		Command: apiModels.CommandSetLocale,
+		Params:  apiModels.SetLocaleParams{Locale: loc},
+	}
+	body, err := json.Marshal(jobStep.ToResource())
+	if err != nil {
+		return errors.Wrap(err, "Error on marshall set locale resource")
+	}
+
+	err = s.conn.WriteMessage(websocket.TextMessage, body)
+	if err != nil {
+		return errors.Wrap(err, "Error on send set locale resource")
+	}
+
+	return nil
+}
+
+// OnEvent adds event handler. Handlers should be added before the connection is established
+func (s *wsService) OnEvent(handler client.EventHandler) {
+	s.handlers = append(s.handlers, handler)
+}
+
+// OnError adds error handler. Handlers should be added before the connection is established
+func (s *wsService) OnError(handler client.ErrorHandler) {
+	s.errorHandlers = append(s.errorHandlers, handler)
+}
+
+func (s *wsService) ConnString() string {
+	return s.url
+}
+
+// read passes events of the connection to handlers until the connection is closed
+func (s *wsService) read(conn *websocket.Conn) {
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			s.mutex.Lock()
+			// the connection is already released by Disconnect
+			requested := s.conn != conn
+			if !requested {
+				s.conn = nil
+			}
+			s.mutex.Unlock()
+
+			conn.Close()
+			if !requested {
+				s.errListener(&wsClosedError{err: err})
+			}
+			return
+		}
+
+		var eventResource apiModels.EventResource
+		err = json.Unmarshal(message, &eventResource)
+		if err != nil {
+			s.errListener(errors.Wrap(err, "Can't unmarshall event resource"))
+			continue
+		}
+		event, err := eventResource.ToEvent()
+		if err != nil {
+			s.errListener(errors.Wrap(err, "Can't convert event resource to the event model"))
+			continue
+		}
+
+		for _, handler := range s.handlers {
+			handler(event)
+		}
+	}
+}
+
+func (s *wsService) errListener(err error) {
+	for _, handler := range s.errorHandlers {
+		handler(err)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// adapterTypePrefix selects adapters by type, i.e. type:nfc
+const adapterTypePrefix = "type:"
+
+// getAdapterId resolves adapter flag to adapter ID
+func (s *appService) getAdapterId() (string, error) {
+	a, err := s.getAdapter(s.adapter)
+	if err != nil {
+		return "", err
+	}
+
+	return a.AdapterID, nil
+}
+
+// getAdapter finds adapter by selector. Adapters of type:<type> are requested with type filter
+func (s *appService) getAdapter(selector string) (apiModels.Adapter, error) {
+	var adapterType *apiModels.AdapterType
+	selector = strings.TrimSpace(selector)
+	if strings.HasPrefix(selector, adapterTypePrefix) {
+		t, ok := apiModels.StringToAdapterType(strings.TrimPrefix(selector, adapterTypePrefix))
+		if !ok {
+			return apiModels.Adapter{}, validationError(errors.New(fmt.Sprintf("Unknown adapter type in %s. Can be one of: nfc, barcode, bluetooth", selector)))
+		}
+		adapterType = &t
+	}
+
+	adapters, err := s.repository.GetAdapters(adapterType, false)
+	if err != nil {
+		return apiModels.Adapter{}, err
+	}
+
+	a, err := selectAdapter(adapters, selector)
+	if err != nil {
+		return apiModels.Adapter{}, validationError(err)
+	}
+
+	return a, nil
+}
+
+// selectAdapter finds the only adapter matching selector. Selector is index in adapters list starting from 1,
+// exact adapter ID, glob pattern or substring of adapter name. Adapters filtered by type are matched with type selector
+func selectAdapter(adapters []apiModels.Adapter, selector string) (apiModels.Adapter, error) {
+	if len(adapters) == 0 {
+		return apiModels.Adapter{}, errors.New("Adapters not found")
+	}
+
+	if i, err := strconv.Atoi(selector); err == nil {
+		if i <= 0 || i > len(adapters) {
+			return apiModels.Adapter{}, errors.New("Can't find adapter with such index")
+		}
+		return adapters[i-1], nil
+	}
+
+	var candidates []apiModels.Adapter
+	for _, a := range adapters {
+		if a.AdapterID == selector {
+			return a, nil
+		}
+		if matchAdapter(a, selector) {
+			candidates = append(candidates, a)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return apiModels.Adapter{}, errors.New(fmt.Sprintf("Can't find adapter %s. Available adapters:\n%s", selector, adapterList(adapters)))
+	case 1:
+		return candidates[0], nil
+	}
+
+	return apiModels.Adapter{}, errors.New(fmt.Sprintf("Adapter %s is ambiguous, it matches %d adapters:\n%s\nUse adapter ID or more specific name", selector, len(candidates), adapterList(candidates)))
+}
+
+func matchAdapter(a apiModels.Adapter, selector string) bool {
+	if strings.HasPrefix(selector, adapterTypePrefix) {
+		return a.Type.String() == strings.TrimPrefix(selector, adapterTypePrefix)
+	}
+
+	name, selector := strings.ToLower(a.Name), strings.ToLower(selector)
+	if strings.ContainsAny(selector, "*?[") {
+		matched, _ := path.Match(selector, name)
+		return matched
+	}
+
+	return strings.Contains(name, selector)
+}
+
+func adapterList(adapters []apiModels.Adapter) string {
+	lines := make([]string, len(adapters))
+	for i, a := range adapters {
+		lines[i] = fmt.Sprintf("%s (%s, %s)", a.Name, a.AdapterID, a.Type)
+	}
+
+	return strings.Join(lines, "\n")
+}
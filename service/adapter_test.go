@@ -0,0 +1,69 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"testing"
+)
+
+func Test_selectAdapter(t *testing.T) {
+	adapters := []apiModels.Adapter{
+		{AdapterID: "5f1b6e2a", Name: "ACS ACR122U PICC Interface 0", Type: apiModels.AdapterTypeNfc},
+		{AdapterID: "9c3d0b7e", Name: "ACS ACR122U PICC Interface 1", Type: apiModels.AdapterTypeNfc},
+		{AdapterID: "1a2b3c4d", Name: "Honeywell Scanner", Type: apiModels.AdapterTypeBarcode},
+	}
+
+	a, err := selectAdapter(adapters, "2")
+	assert.Nil(t, err)
+	assert.Equal(t, "9c3d0b7e", a.AdapterID)
+
+	_, err = selectAdapter(adapters, "4")
+	assert.EqualError(t, err, "Can't find adapter with such index")
+
+	a, err = selectAdapter(adapters, "1a2b3c4d")
+	assert.Nil(t, err)
+	assert.Equal(t, "Honeywell Scanner", a.Name)
+
+	a, err = selectAdapter(adapters, "scanner")
+	assert.Nil(t, err)
+	assert.Equal(t, "1a2b3c4d", a.AdapterID)
+
+	a, err = selectAdapter(adapters, "acs*1")
+	assert.Nil(t, err)
+	assert.Equal(t, "9c3d0b7e", a.AdapterID)
+
+	a, err = selectAdapter(adapters, "type:barcode")
+	assert.Nil(t, err)
+	assert.Equal(t, "1a2b3c4d", a.AdapterID)
+
+	_, err = selectAdapter(adapters, "ACR122U")
+	assert.EqualError(t, err, "Adapter ACR122U is ambiguous, it matches 2 adapters:\n"+
+		"ACS ACR122U PICC Interface 0 (5f1b6e2a, nfc)\n"+
+		"ACS ACR122U PICC Interface 1 (9c3d0b7e, nfc)\n"+
+		"Use adapter ID or more specific name")
+
+	_, err = selectAdapter(adapters, "PN532")
+	assert.EqualError(t, err, "Can't find adapter PN532. Available adapters:\n"+
+		"ACS ACR122U PICC Interface 0 (5f1b6e2a, nfc)\n"+
+		"ACS ACR122U PICC Interface 1 (9c3d0b7e, nfc)\n"+
+		"Honeywell Scanner (1a2b3c4d, barcode)")
+}
+
+func TestAppService_getAdapterId(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+
+	app.adapter = "type:nfc"
+	id, err := app.getAdapterId()
+	assert.Nil(t, err)
+	assert.Equal(t, "mocked adapter id", id)
+
+	app.adapter = "type:barcode"
+	_, err = app.getAdapterId()
+	assert.EqualError(t, err, "Adapters not found")
+
+	app.adapter = "type:usb"
+	_, err = app.getAdapterId()
+	assert.EqualError(t, err, "Unknown adapter type in type:usb. Can be one of: nfc, barcode, bluetooth")
+}
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"context"
+	"github.com/taglme/nfc-cli/config"
+	"github.com/taglme/nfc-cli/kdf"
 	"github.com/taglme/nfc-cli/models"
 	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 	"github.com/urfave/cli/v2"
+	"io"
 	"os"
 	"sort"
+	"sync"
+	"time"
 )
 
 type AppService interface {
@@ -18,26 +25,61 @@ type appService struct {
 	cliApp     cli.App
 	config     opts.Config
 
-	exitCh    chan struct{}
-	adapterId string
+	exitCh chan struct{}
+	// reasons of the exit before all runs are finished
+	interrupted      bool
+	connectionLost   bool
+	deadlineExceeded bool
+	idleExceeded     bool
+	// tagActivity receives tag and run events resetting idle timeout
+	tagActivity chan struct{}
+	// sessionCtx is canceled when the command exits
+	sessionCtx context.Context
+	// sessions are adapters the command runs on, session is the adapter which jobs are added or processed
+	sessions     []*adapterSession
+	session      *adapterSession
+	sessionMutex sync.Mutex
 
 	flagsMap map[string]cli.Flag
 	//  below is arguments controlled by ./flags.go
 	host    string
-	adapter int
+	adapter string
 	repeat  int
 	output  string
 	append  bool
 	timeout int
-	input   string
-	auth    string
-	jobName string
+	// deadline and idle limit time of the whole session and time between tags on the client side
+	deadline time.Duration
+	idle     time.Duration
+	input    string
+	auth     string
+	jobName  string
+	format   string
+	// dumpFormat is a format of dump files written with output flag
+	dumpFormat string
+	configPath string
+	profile    string
+
+	// configFile supplies values of flags which aren't set in command line
+	configFile config.File
+
+	// original process outputs used for json output while human readable messages go to stderr
+	stdout      *os.File
+	colorOutput io.Writer
+	jsonItems   []interface{}
+	jsonMutex   sync.Mutex
+
+	// runSuccessHandler is set by commands which process results of successful runs of the adapter session
+	runSuccessHandler func(session *adapterSession, data interface{})
+	// tagJob adds job for the tag in the field when jobs depend on the tag UID
+	tagJob         func(tag apiModels.Tag) error
+	derivePassword func(uid []byte) (kdf.Password, error)
+	// failedRuns is a number of successful runs which results didn't pass verification
+	failedRuns int
+	// misWrittenTags is a number of tags written with records rendered for other tags
+	misWrittenTags int
 
 	cliStartedCb CbCliStarted
-	ongoingJobs  struct {
-		published int
-		left      int
-	}
 }
 
 type CbCliStarted = func(url string)
@@ -47,6 +89,8 @@ func New(repository ApiService, cb CbCliStarted, config opts.Config) *appService
 		cliStartedCb: cb,
 		repository:   repository,
 		config:       config,
+		session:      &adapterSession{},
+		sessionCtx:   context.Background(),
 		cliApp: cli.App{
 			Name:        "nfc-cli",
 			Description: "Cross-platform CLI for reading NFC tags ",
@@ -56,13 +100,28 @@ func New(repository ApiService, cb CbCliStarted, config opts.Config) *appService
 }
 
 func (s *appService) Start() error {
+	return s.run(os.Args)
+}
+
+func (s *appService) run(args []string) error {
 	s.flagsMap = s.getFlagsMap()
 	s.cliApp.Commands = s.getCommands()
+	s.cliApp.Flags = s.getGlobalFlags()
+	s.cliApp.Before = func(ctx *cli.Context) error {
+		err := s.loadConfig(ctx)
+		if err != nil {
+			return err
+		}
+
+		return s.setupOutput(ctx)
+	}
+	s.cliApp.After = s.restoreOutput
+	s.setConfigBefore(s.cliApp.Commands)
 
 	sort.Sort(cli.FlagsByName(s.cliApp.Flags))
 	sort.Sort(cli.CommandsByName(s.cliApp.Commands))
 
-	return s.cliApp.Run(os.Args)
+	return s.cliApp.Run(args)
 }
 
 func (s *appService) SetRepository(r ApiService) {
@@ -87,17 +146,156 @@ func (s *appService) getCommands() []*cli.Command {
 			},
 			Action: s.cmdAdapters,
 		},
+		{
+			Name:   models.CommandTags,
+			Usage:  "Get tags list in the field of adapter",
+			Flags:  s.getTagsFlags(),
+			Action: s.cmdTags,
+			Subcommands: []*cli.Command{
+				{
+					Name:      models.CommandShow,
+					Usage:     "Get tag details",
+					ArgsUsage: "<tag-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdTagsShow,
+				},
+			},
+		},
+		{
+			Name:  models.CommandJobs,
+			Usage: "Manage adapter jobs on server",
+			Subcommands: []*cli.Command{
+				{
+					Name:  models.CommandList,
+					Usage: "Get jobs list",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+						s.flagsMap[models.FlagStatus],
+						s.flagsMap[models.FlagSortBy],
+						s.flagsMap[models.FlagSortDir],
+						s.flagsMap[models.FlagLimit],
+						s.flagsMap[models.FlagOffset],
+					},
+					Action: s.cmdJobsList,
+				},
+				{
+					Name:      models.CommandShow,
+					Usage:     "Get job details with its steps",
+					ArgsUsage: "<job-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdJobsShow,
+				},
+				{
+					Name:      models.CommandRemove,
+					Usage:     "Delete job",
+					ArgsUsage: "<job-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdJobsRemove,
+				},
+				{
+					Name:      models.CommandPause,
+					Usage:     "Pause job by setting its status to pending",
+					ArgsUsage: "<job-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdJobsPause,
+				},
+				{
+					Name:      models.CommandResume,
+					Usage:     "Resume job by setting its status to active",
+					ArgsUsage: "<job-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdJobsResume,
+				},
+				{
+					Name:      models.CommandLint,
+					Usage:     "Check job file of run command and report all problems with line numbers",
+					ArgsUsage: "<file>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagVar],
+					},
+					Action: s.cmdJobsLint,
+				},
+			},
+		},
+		{
+			Name:  models.CommandRuns,
+			Usage: "Browse history of job runs",
+			Subcommands: []*cli.Command{
+				{
+					Name:  models.CommandList,
+					Usage: "Get runs list",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+						s.flagsMap[models.FlagJobId],
+						s.flagsMap[models.FlagStatus],
+						s.flagsMap[models.FlagSortBy],
+						s.flagsMap[models.FlagSortDir],
+						s.flagsMap[models.FlagLimit],
+						s.flagsMap[models.FlagOffset],
+					},
+					Action: s.cmdRunsList,
+				},
+				{
+					Name:      models.CommandShow,
+					Usage:     "Get run details with tag and step results",
+					ArgsUsage: "<run-id>",
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagHost],
+						s.flagsMap[models.FlagAdapter],
+					},
+					Action: s.cmdRunsShow,
+				},
+			},
+		},
+		{
+			Name:  models.CommandEvents,
+			Usage: "Get events log or follow new events",
+			Flags: []cli.Flag{
+				s.flagsMap[models.FlagHost],
+				s.flagsMap[models.FlagAdapter],
+				s.flagsMap[models.FlagEvent],
+				s.flagsMap[models.FlagFollow],
+				s.flagsMap[models.FlagSortBy],
+				s.flagsMap[models.FlagSortDir],
+				s.flagsMap[models.FlagLimit],
+				s.flagsMap[models.FlagOffset],
+			},
+			Action: s.cmdEvents,
+		},
 		{
 			Name:  models.CommandRead,
 			Usage: "Read tag data with NDEF message",
 			Flags: []cli.Flag{
 				s.flagsMap[models.FlagHost],
 				s.flagsMap[models.FlagAdapter],
+				s.flagsMap[models.FlagAllAdapters],
 				s.flagsMap[models.FlagRepeat],
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
 			},
@@ -115,13 +313,45 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
+				s.flagsMap[models.FlagAnalyze],
+				s.flagsMap[models.FlagDumpFormat],
+				s.flagsMap[models.FlagDiff],
 			},
 			Action: func(ctx *cli.Context) error {
 				return s.withWsConnect(ctx, s.cmdDump)
 			},
+			Subcommands: []*cli.Command{
+				{
+					Name:      models.CommandAnalyze,
+					Usage:     "Print annotated memory layout and NDEF message of dumps saved with output flag",
+					ArgsUsage: "<dump-file>",
+					Action:    s.cmdDumpAnalyze,
+				},
+				{
+					Name:      models.CommandDiff,
+					Usage:     "Compare two dump files page by page",
+					ArgsUsage: "<dump-file-a> <dump-file-b>",
+					Action:    s.cmdDumpDiff,
+				},
+				{
+					Name:      models.CommandExport,
+					Usage:     "Convert dump file to bin, hex, eml, Proxmark JSON or Flipper NFC file",
+					ArgsUsage: "<dump-file>",
+					Action:    s.cmdDumpExport,
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagOutput],
+						s.flagsMap[models.FlagDumpFormat],
+					},
+				},
+			},
 		},
 		{
 			Name:  models.CommandLock,
@@ -133,7 +363,12 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
 			},
@@ -151,7 +386,12 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
 			},
@@ -169,9 +409,15 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
+				s.flagsMap[models.FlagDerive],
 			},
 			Action: func(ctx *cli.Context) error {
 				return s.withWsConnect(ctx, s.cmdRmPwd)
@@ -187,10 +433,16 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
 				s.flagsMap[models.FlagPwd],
+				s.flagsMap[models.FlagDerive],
 			},
 			Action: func(ctx *cli.Context) error {
 				return s.withWsConnect(ctx, s.cmdSetPwd)
@@ -206,7 +458,12 @@ func (s *appService) getCommands() []*cli.Command {
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
 				s.flagsMap[models.FlagTarget],
@@ -216,71 +473,170 @@ func (s *appService) getCommands() []*cli.Command {
 				return s.withWsConnect(ctx, s.cmdTransmit)
 			},
 		},
+		{
+			Name:  models.CommandRestore,
+			Usage: "Write user memory pages of NTAG/Ultralight dump back to the tag and verify them",
+			Flags: []cli.Flag{
+				s.flagsMap[models.FlagHost],
+				s.flagsMap[models.FlagAdapter],
+				s.flagsMap[models.FlagRepeat],
+				s.flagsMap[models.FlagOutput],
+				s.flagsMap[models.FlagAppend],
+				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
+				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
+				s.flagsMap[models.FlagExport],
+				s.flagsMap[models.FlagJobName],
+				s.flagsMap[models.FlagFrom],
+				s.flagsMap[models.FlagAllow],
+			},
+			Action: func(ctx *cli.Context) error {
+				return s.withWsConnect(ctx, s.cmdRestore)
+			},
+		},
 		{
 			Name:  models.CommandWrite,
 			Usage: "Write NDEF message to the tag",
 			Action: func(ctx *cli.Context) error {
 				return s.withWsConnect(ctx, s.cmdWrite)
 			},
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				s.flagsMap[models.FlagHost],
 				s.flagsMap[models.FlagAdapter],
+				s.flagsMap[models.FlagAllAdapters],
 				s.flagsMap[models.FlagRepeat],
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagAuth],
+				s.flagsMap[models.FlagAuthDerive],
+				s.flagsMap[models.FlagKdf],
+				s.flagsMap[models.FlagKeyFile],
 				s.flagsMap[models.FlagExport],
 				s.flagsMap[models.FlagJobName],
-
-				s.flagsMap[models.FlagNdefType],
 				s.flagsMap[models.FlagProtect],
-
-				s.flagsMap[models.FlagNdefTypeRawId],
-				s.flagsMap[models.FlagNdefTypeRawTnf],
-				s.flagsMap[models.FlagNdefTypeType],
-				s.flagsMap[models.FlagNdefTypeRawPayload],
-				s.flagsMap[models.FlagNdefTypeUrl],
-				s.flagsMap[models.FlagNdefTypeText],
-				s.flagsMap[models.FlagNdefTypeLang],
-				s.flagsMap[models.FlagNdefUri],
-				s.flagsMap[models.FlagNdefTypeAarPackage],
-				s.flagsMap[models.FlagNdefTypePhone],
-				s.flagsMap[models.FlagNdefTypeVcardAddressCity],
-				s.flagsMap[models.FlagNdefTypeVcardAddressCountry],
-				s.flagsMap[models.FlagNdefTypeVcardAddressPostalCode],
-				s.flagsMap[models.FlagNdefTypeVcardAddressRegion],
-				s.flagsMap[models.FlagNdefTypeVcardAddressStreet],
-				s.flagsMap[models.FlagNdefTypeVcardEmail],
-				s.flagsMap[models.FlagNdefTypeVcardFirstName],
-				s.flagsMap[models.FlagNdefTypeVcardLastName],
-				s.flagsMap[models.FlagNdefTypeVcardOrganization],
-				s.flagsMap[models.FlagNdefTypeVcardPhoneCell],
-				s.flagsMap[models.FlagNdefTypeVcardPhoneHome],
-				s.flagsMap[models.FlagNdefTypeVcardPhoneWork],
-				s.flagsMap[models.FlagNdefTypeTitle],
-				s.flagsMap[models.FlagNdefTypeVcardSite],
-				s.flagsMap[models.FlagNdefTypeMimeFormat],
-				s.flagsMap[models.FlagNdefTypeMimeContent],
-				s.flagsMap[models.FlagNdefTypeGeoLat],
-				s.flagsMap[models.FlagNdefTypeGeoLon],
+				s.flagsMap[models.FlagVerify],
+				s.flagsMap[models.FlagBatch],
+				s.flagsMap[models.FlagBatchResults],
+				s.flagsMap[models.FlagResume],
+				s.flagsMap[models.FlagSigKey],
+				s.flagsMap[models.FlagCounterStart],
+			}, s.getNdefFlags()...),
+		},
+		{
+			Name:  models.CommandNdef,
+			Usage: "Encode and decode NDEF messages offline",
+			Subcommands: []*cli.Command{
+				{
+					Name:   models.CommandEncode,
+					Usage:  "Encode NDEF message to bytes",
+					Action: s.cmdNdefEncode,
+					Flags: append([]cli.Flag{
+						s.flagsMap[models.FlagEncoding],
+						s.flagsMap[models.FlagTlv],
+					}, s.getNdefFlags()...),
+				},
+				{
+					Name:      models.CommandDecode,
+					Usage:     "Decode NDEF message or TLV blocks of tag memory. Bytes are read from arguments or stdin",
+					ArgsUsage: "[hex-bytes]",
+					Action:    s.cmdNdefDecode,
+					Flags: []cli.Flag{
+						s.flagsMap[models.FlagEncoding],
+					},
+				},
+			},
+		},
+		{
+			Name:  models.CommandConfig,
+			Usage: "Show and set default flag values of config file profiles",
+			Subcommands: []*cli.Command{
+				{
+					Name:   models.CommandShow,
+					Usage:  "Print effective configuration of the profile with sources of values",
+					Action: s.cmdConfigShow,
+				},
+				{
+					Name:      models.CommandGet,
+					Usage:     "Print effective value of the flag",
+					ArgsUsage: "<key>",
+					Action:    s.cmdConfigGet,
+				},
+				{
+					Name:      models.CommandSet,
+					Usage:     "Set default value of the flag in the profile or in defaults without profile flag. Empty value removes the key",
+					ArgsUsage: "<key> <value>",
+					Action:    s.cmdConfigSet,
+				},
 			},
 		},
 		{
 			Name:  models.CommandRun,
 			Usage: "Load jobs from file and send them to server",
 			Action: func(ctx *cli.Context) error {
+				if ctx.Bool(models.FlagValidate) || ctx.Bool(models.FlagDryRun) {
+					return s.cmdRunCheck(ctx)
+				}
 				return s.withWsConnect(ctx, s.cmdRun)
 			},
 			Flags: []cli.Flag{
 				s.flagsMap[models.FlagHost],
 				s.flagsMap[models.FlagAdapter],
+				s.flagsMap[models.FlagAllAdapters],
 				s.flagsMap[models.FlagOutput],
 				s.flagsMap[models.FlagAppend],
 				s.flagsMap[models.FlagTimeout],
+				s.flagsMap[models.FlagDeadline],
+				s.flagsMap[models.FlagIdle],
 				s.flagsMap[models.FlagFile],
+				s.flagsMap[models.FlagVar],
+				s.flagsMap[models.FlagValidate],
+				s.flagsMap[models.FlagDryRun],
 				s.flagsMap[models.FlagJobName],
 			},
 		},
 	}
 }
+
+// getNdefFlags returns flags describing NDEF message records
+func (s *appService) getNdefFlags() []cli.Flag {
+	return []cli.Flag{
+		s.flagsMap[models.FlagNdefType],
+		s.flagsMap[models.FlagRecord],
+		s.flagsMap[models.FlagMessageFile],
+		s.flagsMap[models.FlagNdefTypeRawId],
+		s.flagsMap[models.FlagNdefTypeRawTnf],
+		s.flagsMap[models.FlagNdefTypeType],
+		s.flagsMap[models.FlagNdefTypeRawPayload],
+		s.flagsMap[models.FlagNdefTypeUrl],
+		s.flagsMap[models.FlagNdefTypeText],
+		s.flagsMap[models.FlagNdefTypeLang],
+		s.flagsMap[models.FlagNdefUri],
+		s.flagsMap[models.FlagNdefTypeAarPackage],
+		s.flagsMap[models.FlagNdefTypePhone],
+		s.flagsMap[models.FlagNdefTypeVcardAddressCity],
+		s.flagsMap[models.FlagNdefTypeVcardAddressCountry],
+		s.flagsMap[models.FlagNdefTypeVcardAddressPostalCode],
+		s.flagsMap[models.FlagNdefTypeVcardAddressRegion],
+		s.flagsMap[models.FlagNdefTypeVcardAddressStreet],
+		s.flagsMap[models.FlagNdefTypeVcardEmail],
+		s.flagsMap[models.FlagNdefTypeVcardFirstName],
+		s.flagsMap[models.FlagNdefTypeVcardLastName],
+		s.flagsMap[models.FlagNdefTypeVcardOrganization],
+		s.flagsMap[models.FlagNdefTypeVcardPhoneCell],
+		s.flagsMap[models.FlagNdefTypeVcardPhoneHome],
+		s.flagsMap[models.FlagNdefTypeVcardPhoneWork],
+		s.flagsMap[models.FlagNdefTypeTitle],
+		s.flagsMap[models.FlagNdefTypeVcardSite],
+		s.flagsMap[models.FlagNdefTypeMimeFormat],
+		s.flagsMap[models.FlagNdefTypeMimeContent],
+		s.flagsMap[models.FlagNdefTypeGeoLat],
+		s.flagsMap[models.FlagNdefTypeGeoLon],
+	}
+}
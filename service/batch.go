@@ -0,0 +1,162 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// batch row statuses written to the results file
+const (
+	batchStatusWritten = "written"
+	batchStatusFailed  = "failed"
+	// batchStatusMisWritten is a tag written with the row rendered for another tag
+	batchStatusMisWritten = "miswritten"
+)
+
+var batchResultsHeader = []string{"row", "uid", "status", "time"}
+
+// batchRow is a record written to one tag. Row is a number of the data row in batch file starting from 1
+type batchRow struct {
+	Row     int
+	Records []ndef.NdefPayload
+}
+
+// readBatchFile reads CSV file where header names record fields the same way as write command flags.
+// NDEF type is taken from ndef-type column or from ndefType for files without such column. Empty cells are skipped
+func readBatchFile(filename string, ndefType models.NdefType) ([]batchRow, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read batch file")
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("Batch file is empty")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse batch file")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	var rows []batchRow
+	var problems []string
+	for row := 1; ; row++ {
+		values, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "Can't parse batch file")
+		}
+
+		item := map[string]string{}
+		if len(ndefType) > 0 {
+			item[models.FlagNdefType] = ndefType
+		}
+		for i, v := range values {
+			if len(v) > 0 {
+				item[header[i]] = v
+			}
+		}
+
+		record, err := parseMessageRecord(item)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: row %d: %s", filename, row, err))
+			continue
+		}
+		rows = append(rows, batchRow{Row: row, Records: []ndef.NdefPayload{record}})
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New("Batch file is not valid:\n" + strings.Join(problems, "\n"))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("Batch file doesn't contain any rows")
+	}
+
+	return rows, nil
+}
+
+// batchResultsFilename returns results file next to the batch file, i.e. people.results.csv for people.csv
+func batchResultsFilename(batchFile string) string {
+	ext := filepath.Ext(batchFile)
+
+	return strings.TrimSuffix(batchFile, ext) + ".results.csv"
+}
+
+// pendingBatchRows skips rows written to tags according to the results file. Results file of previous batch
+// is kept untouched unless resume is set
+func pendingBatchRows(rows []batchRow, resultsFile string, resume bool) ([]batchRow, error) {
+	file, err := os.Open(resultsFile)
+	if os.IsNotExist(err) {
+		return rows, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read batch results file")
+	}
+	defer file.Close()
+
+	if !resume {
+		return nil, errors.New(fmt.Sprintf("Batch results file %s already exists. Use resume flag to continue the batch or remove the file", resultsFile))
+	}
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse batch results file")
+	}
+
+	written := map[int]bool{}
+	for i, r := range records {
+		if i == 0 || len(r) < 3 {
+			continue
+		}
+		row, err := strconv.Atoi(r[0])
+		if err == nil && r[2] == batchStatusWritten {
+			written[row] = true
+		}
+	}
+
+	var pending []batchRow
+	for _, r := range rows {
+		if !written[r.Row] {
+			pending = append(pending, r)
+		}
+	}
+
+	return pending, nil
+}
+
+// writeBatchResult appends tag written with the batch row to the results file
+func writeBatchResult(resultsFile string, row int, uid []byte, status string) error {
+	_, err := os.Stat(resultsFile)
+	newFile := os.IsNotExist(err)
+
+	file, err := os.OpenFile(resultsFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Can't open batch results file")
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if newFile {
+		_ = writer.Write(batchResultsHeader)
+	}
+	_ = writer.Write([]string{strconv.Itoa(row), fmt.Sprintf("%X", uid), status, time.Now().Format(time.RFC3339)})
+	writer.Flush()
+
+	return writer.Error()
+}
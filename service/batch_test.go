@@ -0,0 +1,53 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/ndef"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func Test_readBatchFile(t *testing.T) {
+	defer os.Remove("batch_test.csv")
+
+	err := ioutil.WriteFile("batch_test.csv", []byte("first-name,last-name,email\nJohn,Doe,john@tagl.me\nJane,,jane@tagl.me\n"), 0644)
+	assert.Nil(t, err)
+
+	rows, err := readBatchFile("batch_test.csv", "vcard")
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[1].Row)
+	assert.Equal(t, &ndef.NdefRecordPayloadVcard{FirstName: "Jane", Email: "jane@tagl.me"}, rows[1].Records[0])
+
+	_, err = readBatchFile("batch_test.csv", "")
+	assert.EqualError(t, err, "Batch file is not valid:\nbatch_test.csv: row 1: Field ndef-type is required\nbatch_test.csv: row 2: Field ndef-type is required")
+
+	err = ioutil.WriteFile("batch_test.csv", []byte("ndef-type,url,text\nurl,https://tagl.me,\ntext,,Hello\n"), 0644)
+	assert.Nil(t, err)
+	rows, err = readBatchFile("batch_test.csv", "")
+	assert.Nil(t, err)
+	assert.Equal(t, &ndef.NdefRecordPayloadUrl{Url: "https://tagl.me"}, rows[0].Records[0])
+	assert.Equal(t, &ndef.NdefRecordPayloadTypeText{Text: "Hello", Lang: "English"}, rows[1].Records[0])
+}
+
+func Test_pendingBatchRows(t *testing.T) {
+	defer os.Remove("batch_test.results.csv")
+	rows := []batchRow{{Row: 1}, {Row: 2}, {Row: 3}}
+
+	pending, err := pendingBatchRows(rows, "batch_test.results.csv", false)
+	assert.Nil(t, err)
+	assert.Len(t, pending, 3)
+
+	assert.Nil(t, writeBatchResult("batch_test.results.csv", 1, []byte{0x04, 0xA2}, batchStatusWritten))
+	assert.Nil(t, writeBatchResult("batch_test.results.csv", 2, []byte{0x04, 0xA3}, batchStatusFailed))
+
+	_, err = pendingBatchRows(rows, "batch_test.results.csv", false)
+	assert.EqualError(t, err, "Batch results file batch_test.results.csv already exists. Use resume flag to continue the batch or remove the file")
+
+	pending, err = pendingBatchRows(rows, "batch_test.results.csv", true)
+	assert.Nil(t, err)
+	assert.Equal(t, []batchRow{{Row: 2}, {Row: 3}}, pending)
+
+	assert.Equal(t, "people.results.csv", batchResultsFilename("people.csv"))
+}
@@ -3,13 +3,40 @@ package service
 import (
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/dump"
 	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
 	"github.com/taglme/nfc-cli/utils"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
 )
 
 func (s *appService) cmdVersion(*cli.Context) error {
 	s.cliStartedCb(s.host)
+	if s.isJsonOutput() {
+		info, err := s.repository.GetVersion(false)
+		if err != nil {
+			return err
+		}
+
+		return s.printJson(models.VersionOutput{
+			Cli: models.CliInfo{
+				Version:   s.cliApp.Version,
+				Commit:    s.config.Commit,
+				SDKInfo:   s.config.SDK,
+				Platform:  s.config.Platform,
+				BuildTime: s.config.BuildTime,
+			},
+			Server: info,
+		})
+	}
+
 	fmt.Printf("CLI version: %s\n", s.cliApp.Version)
 	if len(s.cliApp.Version) > 0 {
 		fmt.Printf("   Version: %s\n", s.cliApp.Version)
@@ -27,43 +54,318 @@ func (s *appService) cmdVersion(*cli.Context) error {
 		fmt.Printf("   Build time: %s\n", s.config.BuildTime)
 	}
 
-	_, err := s.repository.GetVersion()
+	_, err := s.repository.GetVersion(true)
 
 	return err
 }
 
 func (s *appService) cmdAdapters(*cli.Context) error {
 	s.cliStartedCb(s.host)
-	_, err := s.repository.GetAdapters(true)
+	adapters, err := s.repository.GetAdapters(nil, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
 
-	return err
+	items := make([]interface{}, len(adapters))
+	for i, a := range adapters {
+		items[i] = a.ToResource()
+	}
+
+	return s.printJsonList(items, nil)
+}
+
+func (s *appService) cmdTags(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	var tagType *apiModels.TagType
+	if t := ctx.String(models.FlagTagType); len(t) > 0 {
+		tt, ok := apiModels.StringToTagType(t)
+		if !ok {
+			return errors.New("Wrong type flag value. Can be either \"nfc\", \"barcode\" or \"bluetooth\".")
+		}
+		tagType = &tt
+	}
+
+	adapterId, err := s.getAdapterId()
+	if err != nil {
+		return err
+	}
+
+	tags, err := s.repository.GetTags(adapterId, tagType, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	items := make([]interface{}, len(tags))
+	for i, t := range tags {
+		items[i] = t.ToResource()
+	}
+
+	return s.printJsonList(items, nil)
+}
+
+func (s *appService) cmdTagsShow(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	tagId := ctx.Args().First()
+	if len(tagId) == 0 {
+		return errors.New("Tag ID argument is required")
+	}
+
+	adapterId, err := s.getAdapterId()
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.repository.GetTag(adapterId, tagId, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	return s.printJson(tag.ToResource())
+}
+
+func (s *appService) cmdJobsList(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	filter, err := parseJobFilterFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	adapterId, err := s.getAdapterId()
+	if err != nil {
+		return err
+	}
+
+	jobs, pageInfo, err := s.repository.GetJobs(adapterId, filter, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	items := make([]interface{}, len(jobs))
+	for i, j := range jobs {
+		items[i] = j.ToResource()
+	}
+
+	return s.printJsonList(items, &pageInfo)
+}
+
+func (s *appService) cmdJobsShow(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	jobId, adapterId, err := s.getJobArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := s.repository.GetJob(adapterId, jobId, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	return s.printJson(job.ToResource())
+}
+
+func (s *appService) cmdJobsRemove(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	jobId, adapterId, err := s.getJobArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.DeleteJob(adapterId, jobId)
+	if err != nil {
+		return err
+	}
+
+	if s.isJsonOutput() {
+		return s.printJson(models.JobDeletedOutput{JobID: jobId, Deleted: true})
+	}
+
+	fmt.Printf("Job %s: deleted\n", jobId)
+
+	return nil
+}
+
+func (s *appService) cmdJobsPause(ctx *cli.Context) error {
+	return s.updateJobStatus(ctx, apiModels.JobStatusPending)
+}
+
+func (s *appService) cmdJobsResume(ctx *cli.Context) error {
+	return s.updateJobStatus(ctx, apiModels.JobStatusActive)
+}
+
+func (s *appService) updateJobStatus(ctx *cli.Context, status apiModels.JobStatus) error {
+	s.cliStartedCb(s.host)
+
+	jobId, adapterId, err := s.getJobArgs(ctx)
+	if err != nil {
+		return err
+	}
+
+	job, err := s.repository.UpdateJobStatus(adapterId, jobId, status, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	return s.printJson(job.ToResource())
+}
+
+func (s *appService) getJobArgs(ctx *cli.Context) (jobId string, adapterId string, err error) {
+	jobId = ctx.Args().First()
+	if len(jobId) == 0 {
+		return jobId, adapterId, errors.New("Job ID argument is required")
+	}
+
+	adapterId, err = s.getAdapterId()
+
+	return jobId, adapterId, err
+}
+
+func (s *appService) cmdRunsList(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	filter, err := parseRunFilterFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	adapterId, err := s.getAdapterId()
+	if err != nil {
+		return err
+	}
+
+	runs, pageInfo, err := s.repository.GetRuns(adapterId, filter, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	items := make([]interface{}, len(runs))
+	for i, r := range runs {
+		items[i] = r.ToResource()
+	}
+
+	return s.printJsonList(items, &pageInfo)
+}
+
+func (s *appService) cmdRunsShow(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	runId := ctx.Args().First()
+	if len(runId) == 0 {
+		return errors.New("Run ID argument is required")
+	}
+
+	adapterId, err := s.getAdapterId()
+	if err != nil {
+		return err
+	}
+
+	run, err := s.repository.GetRun(adapterId, runId, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	return s.printJson(run.ToResource())
+}
+
+func (s *appService) cmdEvents(ctx *cli.Context) error {
+	s.cliStartedCb(s.host)
+
+	filter, err := parseEventFilterFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	// events of all adapters are shown unless adapter is set explicitly
+	var adapterId *string
+	if ctx.IsSet(models.FlagAdapter) {
+		id, err := s.getAdapterId()
+		if err != nil {
+			return err
+		}
+		adapterId = &id
+	}
+
+	if ctx.Bool(models.FlagFollow) {
+		return s.followEvents(adapterId, filter.Name)
+	}
+
+	events, pageInfo, err := s.repository.GetEvents(adapterId, filter, !s.isJsonOutput())
+	if err != nil || !s.isJsonOutput() {
+		return err
+	}
+
+	items := make([]interface{}, len(events))
+	for i, e := range events {
+		items[i] = e.ToResource()
+	}
+
+	return s.printJsonList(items, &pageInfo)
+}
+
+func (s *appService) followEvents(adapterId *string, name *apiModels.EventName) error {
+	s.exitCh = make(chan struct{}, 1)
+
+	eHandler := func(e apiModels.Event) {
+		if s.isJsonOutput() {
+			s.printJsonItem(e.ToResource())
+		}
+	}
+
+	err := s.repository.FollowEvents(adapterId, name, !s.isJsonOutput(), eHandler, s.errorHandler)
+	if err != nil {
+		return errors.Wrap(err, "Can't establish the WS connection")
+	}
+	defer func() {
+		err = s.repository.StopWsConnection()
+		if err != nil {
+			log.Printf("Error on WS connection close: %s", err)
+		}
+	}()
+
+	fmt.Println("Waiting for events...")
+
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt)
+	defer signal.Stop(signalCh)
+	go func() {
+		for range signalCh {
+			fmt.Println("\nExiting...")
+			s.exit()
+			return
+		}
+	}()
+	<-s.exitCh
+
+	return s.flushJsonItems()
 }
 
 func (s *appService) cmdRead(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
 
+	p := models.GenericJobParams{
+		Cmd:       models.CommandRead,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddGenericJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandRead,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, s.addGenericJob)
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
@@ -71,27 +373,44 @@ func (s *appService) cmdRead(ctx *cli.Context) error {
 func (s *appService) cmdDump(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 	export := ctx.Bool(models.FlagExport)
 
+	if !export {
+		s.dumpFormat, err = getDumpFormat(ctx.String(models.FlagDumpFormat))
+		if err != nil {
+			return validationError(err)
+		}
+	}
+
+	p := models.GenericJobParams{
+		Cmd:       models.CommandDump,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddGenericJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandDump,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, s.addGenericJob)
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
+
+	var reference *dump.Dump
+	if diff := ctx.String(models.FlagDiff); len(diff) > 0 {
+		d, err := dump.ReadSingleFile(diff)
+		if err != nil {
+			return validationError(err)
+		}
+		reference = &d
+	}
+	if ctx.Bool(models.FlagAnalyze) || reference != nil {
+		s.runSuccessHandler = s.dumpRunHandler(ctx.Bool(models.FlagAnalyze), ctx.String(models.FlagDiff), reference)
+	}
 
 	return s.exportData(export, nj)
 }
@@ -99,26 +418,24 @@ func (s *appService) cmdDump(ctx *cli.Context) error {
 func (s *appService) cmdLock(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
+	p := models.GenericJobParams{
+		Cmd:       models.CommandLock,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddGenericJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandLock,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			JobName:   s.jobName,
-		},
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, s.addGenericJob)
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
@@ -126,22 +443,22 @@ func (s *appService) cmdLock(ctx *cli.Context) error {
 func (s *appService) cmdFormat(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
+	p := models.GenericJobParams{
+		Cmd:       models.CommandFormat,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddGenericJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandFormat,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, s.addGenericJob)
 	if err != nil {
 		return err
 	}
@@ -152,61 +469,79 @@ func (s *appService) cmdFormat(ctx *cli.Context) error {
 func (s *appService) cmdRmPwd(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
+	p := models.GenericJobParams{
+		Cmd:       models.CommandRmpwd,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddGenericJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandRmpwd,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive) || ctx.Bool(models.FlagDerive), false, s.addGenericJob)
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
 
 func (s *appService) cmdSetPwd(ctx *cli.Context) error {
+	derive := ctx.Bool(models.FlagDerive)
+	if derive == ctx.IsSet(models.FlagPwd) {
+		return validationError(errors.New("Password should be set with either pwd or derive flag"))
+	}
+
 	password, err := utils.ParseHexString(ctx.String(models.FlagPwd))
 	if err != nil {
-		return errors.Wrap(err, "Can't parse password arg")
+		return validationError(errors.Wrap(err, "Can't parse password arg"))
 	}
 
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
+	p := models.GenericJobParams{
+		Cmd:       models.CommandSetpwd,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddSetPwdJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandSetpwd,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-		password,
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), derive, func(p models.GenericJobParams, tag apiModels.Tag) (*apiModels.NewJob, error) {
+		if !derive {
+			_, nj, err := s.repository.AddSetPwdJob(p, password, nil)
+			return nj, err
+		}
+
+		pwd, err := s.derivePassword(tag.Uid)
+		if err != nil {
+			return nil, err
+		}
+		// password isn't set without PACK page, so the tag isn't left with factory PACK
+		commands := packWriteCommands(tag.Product, pwd.Pack)
+		if len(commands) == 0 {
+			return nil, errors.New(fmt.Sprintf("PACK page of tag product \"%s\" is unknown. Password isn't set", tag.Product))
+		}
+
+		_, nj, err := s.repository.AddSetPwdJob(p, pwd.Pwd, commands)
+		return nj, err
+	})
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
@@ -214,40 +549,39 @@ func (s *appService) cmdSetPwd(ctx *cli.Context) error {
 func (s *appService) cmdTransmit(ctx *cli.Context) error {
 	target := ctx.String(models.FlagTarget)
 	if target != "tag" && target != "adapter" {
-		return errors.New("Wrong target flag value. Can be either \"tag\" or \"adapter\".")
+		return validationError(errors.New("Wrong target flag value. Can be either \"tag\" or \"adapter\"."))
 	}
 
 	txBytes, err := utils.ParseHexString(ctx.String(models.FlagTxBytes))
 	if err != nil {
-		return errors.Wrap(err, "Can't parse tx bytes string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse tx bytes string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	export := ctx.Bool(models.FlagExport)
 
+	p := models.GenericJobParams{
+		Cmd:       models.CommandTransmit,
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
 	var nj interface{}
-	_, nj, err = s.repository.AddTransmitJob(
-		models.GenericJobParams{
-			Cmd:       models.CommandTransmit,
-			AdapterId: s.adapterId,
-			Repeat:    s.repeat,
-			Expire:    s.timeout,
-			Auth:      auth,
-			Export:    export,
-			JobName:   s.jobName,
-		},
-		txBytes,
-		target,
-	)
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, func(p models.GenericJobParams, _ apiModels.Tag) (*apiModels.NewJob, error) {
+		_, nj, err := s.repository.AddTransmitJob(p, txBytes, target)
+		return nj, err
+	})
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
@@ -255,50 +589,174 @@ func (s *appService) cmdTransmit(ctx *cli.Context) error {
 func (s *appService) cmdWrite(ctx *cli.Context) error {
 	auth, err := utils.ParseHexString(s.auth)
 	if err != nil {
-		return errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\"")
-	}
-
-	payload, err := s.parseNdefPayloadFlags(ctx)
-	if err != nil {
-		return err
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	protect := ctx.Bool(models.FlagProtect)
+	verify := ctx.Bool(models.FlagVerify)
 	export := ctx.Bool(models.FlagExport)
 
-	var nj interface{}
-	_, nj, err = s.repository.AddWriteJob(
-		models.GenericJobParams{
+	w := &writeSession{
+		params: models.GenericJobParams{
 			Cmd:       models.CommandTransmit,
-			AdapterId: s.adapterId,
+			AdapterId: s.adapterId(),
 			Repeat:    s.repeat,
 			Expire:    s.timeout,
 			Auth:      auth,
 			Export:    export,
 			JobName:   s.jobName,
 		},
-		payload,
-		protect,
-	)
+		verify:  verify,
+		protect: protect,
+	}
+
+	batchFile := ctx.String(models.FlagBatch)
+	if len(batchFile) > 0 {
+		w.rows, w.resultsFile, err = s.parseBatchFlags(ctx, batchFile)
+		if err != nil {
+			return validationError(err)
+		}
+		fmt.Printf("Writing %d rows of %s. Results are written to %s\n", len(w.rows), batchFile, w.resultsFile)
+	} else {
+		w.records, err = s.parseNdefMessageFlags(ctx)
+		if err != nil {
+			return validationError(err)
+		}
+	}
+
+	w.template, err = s.parseTemplateFlags(ctx, w.sourceRecords())
+	if err != nil {
+		return validationError(err)
+	}
+
+	if ctx.Bool(models.FlagAuthDerive) {
+		if export || len(auth) > 0 {
+			return validationError(errors.New("Flag auth-derive can't be used with auth and export flags"))
+		}
+		s.derivePassword, err = parseDeriveFlags(ctx)
+		if err != nil {
+			return validationError(err)
+		}
+		w.deriveAuth = true
+	}
+
+	if w.chained() {
+		if s.multiAdapter() {
+			return validationError(errors.New("Batch, placeholders and auth-derive flags can't be used with several adapters as tags are written one by one"))
+		}
+		w.params.Repeat = 1
+	}
+	s.session.published = w.total(s.repeat)
+	s.session.left = w.total(s.repeat)
+
+	if verify || w.chained() {
+		s.runSuccessHandler = s.writeRunHandler(w)
+	}
+
+	// records with placeholders and derived passwords are known when the tag is in the field
+	if w.perTag() {
+		fmt.Println("Waiting for tag...")
+		go s.addTagWriteJob(w, s.session, nil, w.sourceRecords())
+		return nil
+	}
+
+	var nj interface{}
+	_, nj, err = s.repository.AddWriteJob(w.params, w.tagRecords(), w.protect, w.verify)
 	if err != nil {
 		return err
 	}
-	s.ongoingJobs.published = s.repeat
-	s.ongoingJobs.left = s.repeat
 
 	return s.exportData(export, nj)
 }
 
+// parseTemplateFlags returns template when records have placeholders. Records are rendered once to check the template
+func (s *appService) parseTemplateFlags(ctx *cli.Context, records []ndef.NdefPayload) (*tagTemplate, error) {
+	if !hasPlaceholders(records) {
+		return nil, nil
+	}
+	if ctx.Bool(models.FlagExport) {
+		return nil, errors.New("Records with placeholders can't be exported as they are rendered for every tag")
+	}
+
+	key, err := utils.ParseHexString(ctx.String(models.FlagSigKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse sig-key string. It should be HEX string")
+	}
+
+	t := &tagTemplate{key: key, counter: ctx.Int(models.FlagCounterStart), now: time.Now}
+	probe := *t
+	_, err = probe.render(records, make([]byte, 7))
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// parseBatchFlags returns batch rows which are not written yet and results file name
+func (s *appService) parseBatchFlags(ctx *cli.Context, batchFile string) ([]batchRow, string, error) {
+	if ctx.Bool(models.FlagExport) {
+		return nil, "", errors.New("Flags batch and export can't be used together")
+	}
+	if len(ctx.StringSlice(models.FlagRecord)) > 0 || ctx.IsSet(models.FlagMessageFile) {
+		return nil, "", errors.New("Flags batch, record and message-file can't be used together")
+	}
+
+	rows, err := readBatchFile(batchFile, ctx.String(models.FlagNdefType))
+	if err != nil {
+		return nil, "", err
+	}
+
+	resultsFile := ctx.String(models.FlagBatchResults)
+	if len(resultsFile) == 0 {
+		resultsFile = batchResultsFilename(batchFile)
+	}
+
+	rows, err = pendingBatchRows(rows, resultsFile, ctx.Bool(models.FlagResume))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(rows) == 0 {
+		return nil, "", errors.New(fmt.Sprintf("All rows of %s are written. See %s", batchFile, resultsFile))
+	}
+
+	return rows, resultsFile, nil
+}
+
 func (s *appService) cmdRun(ctx *cli.Context) error {
+	vars, err := parseVarFlags(ctx.StringSlice(models.FlagVar))
+	if err != nil {
+		return validationError(err)
+	}
+
 	file := ctx.String(models.FlagFile)
-	jobsPublished, err := s.repository.AddJobFromFile(s.adapterId, file, models.GenericJobParams{Expire: s.timeout, JobName: s.jobName})
+	jobsPublished, err := s.repository.AddJobFromFile(s.adapterId(), file, models.GenericJobParams{Expire: s.timeout, JobName: s.jobName, Vars: vars})
 
-	s.ongoingJobs.published = jobsPublished
-	s.ongoingJobs.left = jobsPublished
+	s.session.published = jobsPublished
+	s.session.left = jobsPublished
 
 	return err
 }
 
+// parseVarFlags parses job file variables given as NAME=value
+func parseVarFlags(flags []string) (map[string]string, error) {
+	vars := make(map[string]string, len(flags))
+	for _, f := range flags {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 {
+			return nil, errors.New(fmt.Sprintf("Variable %s should be in form \"NAME=value\"", f))
+		}
+		vars[parts[0]] = parts[1]
+	}
+
+	return vars, nil
+}
+
+func (s *appService) addGenericJob(p models.GenericJobParams, _ apiModels.Tag) (*apiModels.NewJob, error) {
+	_, nj, err := s.repository.AddGenericJob(p)
+	return nj, err
+}
+
 func (s *appService) exportData(export bool, data interface{}) error {
 	if export && data != nil {
 		err := s.writeToFile(s.output, data)
@@ -309,3 +767,83 @@ func (s *appService) exportData(export bool, data interface{}) error {
 
 	return nil
 }
+
+func (s *appService) cmdNdefEncode(ctx *cli.Context) error {
+	encoding := ctx.String(models.FlagEncoding)
+	if encoding != "hex" && encoding != "bin" {
+		return errors.New("Wrong encoding flag value. Can be either \"hex\" or \"bin\".")
+	}
+
+	records, err := s.parseNdefMessageFlags(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := ndef.Encode(records)
+	if err != nil {
+		return errors.Wrap(err, "Can't encode NDEF message")
+	}
+
+	tlv := ctx.Bool(models.FlagTlv)
+	if tlv {
+		data = ndef.WrapTlv(data)
+	}
+
+	if s.isJsonOutput() {
+		return s.printJson(models.NdefEncodedOutput{Hex: fmt.Sprintf("% X", data), Length: len(data), Tlv: tlv})
+	}
+
+	if encoding == "bin" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	fmt.Printf("% X\n", data)
+
+	return nil
+}
+
+func (s *appService) cmdNdefDecode(ctx *cli.Context) error {
+	encoding := ctx.String(models.FlagEncoding)
+	if encoding != "hex" && encoding != "bin" {
+		return errors.New("Wrong encoding flag value. Can be either \"hex\" or \"bin\".")
+	}
+
+	var input []byte
+	var err error
+	if ctx.Args().Len() > 0 {
+		input = []byte(strings.Join(ctx.Args().Slice(), " "))
+	} else {
+		input, err = ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return errors.Wrap(err, "Can't read NDEF message from stdin")
+		}
+	}
+
+	data := input
+	if encoding == "hex" {
+		data, err = utils.ParseHexString(strings.Join(strings.Fields(string(input)), ""))
+		if err != nil {
+			return errors.Wrap(err, "Can't parse NDEF message. It should be HEX string i.e. \"D1 01 04 55 03 61 2E 6D\"")
+		}
+	}
+
+	payloads, records, err := ndef.Decode(data)
+	if err != nil {
+		return errors.Wrap(err, "Can't decode NDEF message")
+	}
+
+	if s.isJsonOutput() {
+		maps := ndefPayloadsToMaps(payloads)
+		items := make([]interface{}, len(maps))
+		for i, m := range maps {
+			items[i] = m
+		}
+
+		return s.printJsonList(items, nil)
+	}
+
+	printNdefRecords(payloads, records)
+
+	return nil
+}
@@ -54,6 +54,99 @@ func Test_cmdAdapters(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func Test_cmdTags(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	config := opts.Config{}
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, config)
+	os.Args = []string{"nfc-cli", models.CommandTags, "--" + models.FlagTagType, "nfc"}
+	err := app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandTags, "--" + models.FlagTagType, "wrong"}
+	err = app.Start()
+	assert.Error(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandTags, models.CommandShow, "mocked tag id"}
+	err = app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandTags, models.CommandShow}
+	err = app.Start()
+	assert.EqualError(t, err, "Tag ID argument is required")
+}
+
+func Test_cmdJobs(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	config := opts.Config{}
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, config)
+
+	os.Args = []string{"nfc-cli", models.CommandJobs, models.CommandList, "--" + models.FlagStatus, "active", "--" + models.FlagLimit, "10"}
+	err := app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandJobs, models.CommandList, "--" + models.FlagStatus, "done"}
+	err = app.Start()
+	assert.Error(t, err)
+
+	for _, cmd := range []string{models.CommandShow, models.CommandRemove, models.CommandPause, models.CommandResume} {
+		os.Args = []string{"nfc-cli", models.CommandJobs, cmd, "mocked job id"}
+		err = app.Start()
+		assert.Nil(t, err)
+
+		os.Args = []string{"nfc-cli", models.CommandJobs, cmd}
+		err = app.Start()
+		assert.EqualError(t, err, "Job ID argument is required")
+	}
+}
+
+func Test_cmdRuns(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	config := opts.Config{}
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, config)
+
+	os.Args = []string{"nfc-cli", models.CommandRuns, models.CommandList, "--" + models.FlagJobId, "mocked job id", "--" + models.FlagStatus, "error"}
+	err := app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandRuns, models.CommandList, "--" + models.FlagStatus, "pending"}
+	err = app.Start()
+	assert.Error(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandRuns, models.CommandShow, "mocked run id"}
+	err = app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandRuns, models.CommandShow}
+	err = app.Start()
+	assert.EqualError(t, err, "Run ID argument is required")
+}
+
+func Test_cmdEvents(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	config := opts.Config{}
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, config)
+
+	os.Args = []string{"nfc-cli", models.CommandEvents, "--" + models.FlagEvent, "tag_discovery"}
+	err := app.Start()
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandEvents, "--" + models.FlagAdapter, "2"}
+	err = app.Start()
+	assert.EqualError(t, err, "Can't find adapter with such index")
+
+	os.Args = []string{"nfc-cli", models.CommandEvents, "--" + models.FlagEvent, "unknown_event"}
+	err = app.Start()
+	assert.Error(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandEvents, "--" + models.FlagFollow, "--" + models.FlagAdapter, "1"}
+	err = app.Start()
+	assert.Nil(t, err)
+}
+
 func Test_cmdRead(t *testing.T) {
 	rep := mock.NewRepositoryService(nil)
 	config := opts.Config{}
@@ -146,6 +239,38 @@ func Test_cmdWrite(t *testing.T) {
 	assert.Nil(t, err)
 	err = os.Remove(app.output)
 	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandWrite, "--" + models.FlagRecord, "url:url=http://url.ulr", "--" + models.FlagRecord, "aar:package-name=me.tagl", "--" + models.FlagExport, "--" + models.FlagOutput, "cmd_test_file.json"}
+	err = app.Start()
+	assert.Nil(t, err)
+	err = os.Remove(app.output)
+	assert.Nil(t, err)
+
+	os.Args = []string{"nfc-cli", models.CommandWrite, "--" + models.FlagRecord, "url:url=http://url.ulr", "--" + models.FlagRecord, "aar:package-name=", "--" + models.FlagExport}
+	err = app.Start()
+	assert.EqualError(t, err, "Record 2: Package name value can't be empty")
+
+	os.Args = []string{"nfc-cli", models.CommandWrite, "--" + models.FlagExport}
+	err = app.Start()
+	assert.EqualError(t, err, "One of ndef-type, record or message-file flags should be set")
+}
+
+func Test_cmdNdef(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	config := opts.Config{}
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, config)
+
+	out, err := startWithStdout(t, app, models.CommandNdef, models.CommandEncode, "--"+models.FlagNdefType, "url", "--"+models.NdefTypeUrl, "https://tagl.me", "--"+models.FlagTlv)
+	assert.Nil(t, err)
+	assert.Equal(t, "03 0C D1 01 08 55 04 74 61 67 6C 2E 6D 65 FE\n", out)
+
+	out, err = startWithStdout(t, app, models.CommandNdef, models.CommandDecode, "03 0C D1 01 08 55 04 74 61 67 6C 2E 6D 65 FE")
+	assert.Nil(t, err)
+	assert.Equal(t, "Record 1: url (flags MB ME SR, TNF 1, type \"U\", payload 8 bytes)\n   url: https://tagl.me\n", out)
+
+	_, err = startWithStdout(t, app, models.CommandNdef, models.CommandDecode, "D1 01")
+	assert.EqualError(t, err, "Can't decode NDEF message: Unexpected end of NDEF message at byte 2")
 }
 
 //
@@ -174,3 +299,12 @@ func Test_cmdWrite(t *testing.T) {
 //		}
 //	}()
 //}
+
+func Test_parseVarFlags(t *testing.T) {
+	vars, err := parseVarFlags([]string{"URL=https://tagl.me/?a=b", "EMPTY="})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"URL": "https://tagl.me/?a=b", "EMPTY": ""}, vars)
+
+	_, err = parseVarFlags([]string{"URL"})
+	assert.EqualError(t, err, "Variable URL should be in form \"NAME=value\"")
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"flag"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/config"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/urfave/cli/v2"
+	"os"
+	"sort"
+	"strings"
+)
+
+// configEnvPrefix is a prefix of environment variables overriding config file values, i.e. NFC_CLI_HOST for host flag
+const configEnvPrefix = "NFC_CLI_"
+
+// sources of config values which aren't environment variables
+const (
+	configSourceDefaults = "defaults"
+	configSourceProfile  = "profile"
+	configSourceFlag     = "flag default"
+)
+
+func configEnvName(key string) string {
+	return configEnvPrefix + strings.ToUpper(strings.Replace(key, "-", "_", -1))
+}
+
+// loadConfig reads config file and sets global flags. Profile isn't checked here as config set command creates profiles
+func (s *appService) loadConfig(ctx *cli.Context) error {
+	var err error
+	s.configFile, err = config.Load(s.configPath)
+	if err != nil {
+		return err
+	}
+
+	return s.applyConfig(ctx, s.cliApp.Flags)
+}
+
+// setConfigBefore sets flags of commands from environment variables and config file before commands run
+func (s *appService) setConfigBefore(commands []*cli.Command) {
+	for _, c := range commands {
+		if c.Name == models.CommandConfig {
+			continue
+		}
+
+		flags := c.Flags
+		c.Before = func(ctx *cli.Context) error {
+			_, err := s.configFile.Values(s.profile)
+			if err != nil {
+				return err
+			}
+
+			return s.applyConfig(ctx, flags)
+		}
+		s.setConfigBefore(c.Subcommands)
+	}
+}
+
+// applyConfig sets flags which aren't set in command line. Environment variables override values of the profile,
+// values of the profile override defaults of config file
+func (s *appService) applyConfig(ctx *cli.Context, flags []cli.Flag) error {
+	for _, f := range flags {
+		name := f.Names()[0]
+		if name == models.FlagProfile || name == models.FlagConfig || ctx.IsSet(name) {
+			continue
+		}
+
+		value, source, ok := s.configValue(name)
+		if !ok {
+			continue
+		}
+		err := ctx.Set(name, value)
+		if err != nil {
+			return errors.Wrapf(err, "Can't set %s flag with value of %s", name, source)
+		}
+	}
+
+	return nil
+}
+
+// configValue returns value of the flag from environment variable or config file and the source of the value
+func (s *appService) configValue(key string) (string, string, bool) {
+	env := configEnvName(key)
+	if v := os.Getenv(env); len(v) > 0 {
+		return v, env, true
+	}
+	if v, ok := s.configFile.Profiles[s.profile][key]; ok && len(s.profile) > 0 {
+		return v, configSourceProfile + " " + s.profile, true
+	}
+	if v, ok := s.configFile.Defaults[key]; ok {
+		return v, configSourceDefaults, true
+	}
+
+	return "", "", false
+}
+
+// configFlag returns flag which can be set in config file
+func (s *appService) configFlag(key string) (cli.Flag, error) {
+	if f, ok := s.flagsMap[key]; ok {
+		return f, nil
+	}
+	for _, f := range s.cliApp.Flags {
+		name := f.Names()[0]
+		if name == key && name != models.FlagProfile && name != models.FlagConfig {
+			return f, nil
+		}
+	}
+
+	return nil, errors.New(fmt.Sprintf("Unknown config key %s. Keys are named as command flags, i.e. %s", key, models.FlagHost))
+}
+
+func (s *appService) cmdConfigShow(*cli.Context) error {
+	_, err := s.configFile.Values(s.profile)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]bool{}
+	for k := range s.configFile.Defaults {
+		keys[k] = true
+	}
+	for k := range s.configFile.Profiles[s.profile] {
+		keys[k] = true
+	}
+	for k := range s.flagsMap {
+		if _, ok := os.LookupEnv(configEnvName(k)); ok {
+			keys[k] = true
+		}
+	}
+	var sorted []string
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	out := models.ConfigOutput{File: s.configPath, Profile: s.profile, Values: []models.ConfigValueOutput{}}
+	for _, k := range sorted {
+		value, source, ok := s.configValue(k)
+		if ok {
+			out.Values = append(out.Values, models.ConfigValueOutput{Key: k, Value: value, Source: source})
+		}
+	}
+
+	if s.isJsonOutput() {
+		return s.printJson(out)
+	}
+
+	fmt.Printf("Config file: %s\n", out.File)
+	if len(out.Profile) > 0 {
+		fmt.Printf("Profile: %s\n", out.Profile)
+	}
+	if len(out.Values) == 0 {
+		fmt.Println("Config values are not set")
+	}
+	for _, v := range out.Values {
+		fmt.Printf("%s: %s (%s)\n", v.Key, v.Value, v.Source)
+	}
+
+	return nil
+}
+
+func (s *appService) cmdConfigGet(ctx *cli.Context) error {
+	key := ctx.Args().First()
+	if len(key) == 0 {
+		return errors.New("Key argument is required")
+	}
+	f, err := s.configFlag(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.configFile.Values(s.profile)
+	if err != nil {
+		return err
+	}
+
+	value, source, ok := s.configValue(key)
+	if !ok {
+		source = configSourceFlag
+		if v, isValue := f.(cli.DocGenerationFlag); isValue {
+			value = v.GetValue()
+		}
+	}
+
+	if s.isJsonOutput() {
+		return s.printJson(models.ConfigValueOutput{Key: key, Value: value, Source: source})
+	}
+
+	fmt.Println(value)
+	return nil
+}
+
+func (s *appService) cmdConfigSet(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("Key and value arguments are required")
+	}
+	key, value := ctx.Args().Get(0), ctx.Args().Get(1)
+	f, err := s.configFlag(key)
+	if err != nil {
+		return err
+	}
+
+	if len(value) > 0 {
+		set := flag.NewFlagSet(key, flag.ContinueOnError)
+		err = f.Apply(set)
+		if err == nil {
+			err = set.Set(key, value)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Wrong value of %s", key)
+		}
+	}
+
+	s.configFile.Set(s.profile, key, value)
+	err = s.configFile.Save(s.configPath)
+	if err != nil {
+		return err
+	}
+
+	target := configSourceDefaults
+	if len(s.profile) > 0 {
+		target = configSourceProfile + " " + s.profile
+	}
+	if len(value) == 0 {
+		fmt.Printf("%s is removed from %s of %s\n", key, target, s.configPath)
+	} else {
+		fmt.Printf("%s is set to %s in %s of %s\n", key, value, target, s.configPath)
+	}
+
+	return nil
+}
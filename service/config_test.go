@@ -0,0 +1,76 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/config"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/opts"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func runConfigTestApp(t *testing.T, path string, args ...string) (string, error) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	var err error
+	out := captureStdout(t, func() {
+		err = app.run(append([]string{"nfc-cli", "--config", path}, args...))
+	})
+
+	return out, err
+}
+
+func TestAppService_config(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfc-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nfc-cli", "config.yaml")
+
+	_, err = runConfigTestApp(t, path, "config", "set", "host", "10.0.0.1:3011")
+	assert.Nil(t, err)
+	_, err = runConfigTestApp(t, path, "--profile", "line2", "config", "set", "host", "10.0.0.2:3011")
+	assert.Nil(t, err)
+	_, err = runConfigTestApp(t, path, "--profile", "line2", "config", "set", "ndef-type", "url")
+	assert.Nil(t, err)
+
+	_, err = runConfigTestApp(t, path, "config", "set", "timeout", "soon")
+	assert.NotNil(t, err)
+	_, err = runConfigTestApp(t, path, "config", "set", "hostname", "10.0.0.1:3011")
+	assert.EqualError(t, err, "Unknown config key hostname. Keys are named as command flags, i.e. host")
+
+	f, err := config.Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, config.File{
+		Defaults: map[string]string{"host": "10.0.0.1:3011"},
+		Profiles: map[string]map[string]string{"line2": {"host": "10.0.0.2:3011", "ndef-type": "url"}},
+	}, f)
+
+	out, err := runConfigTestApp(t, path, "config", "get", "host")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:3011\n", out)
+	out, err = runConfigTestApp(t, path, "--profile", "line2", "config", "get", "host")
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.2:3011\n", out)
+	out, err = runConfigTestApp(t, path, "config", "get", "timeout")
+	assert.Nil(t, err)
+	assert.Equal(t, "60\n", out)
+
+	os.Setenv("NFC_CLI_HOST", "10.0.0.3:3011")
+	out, err = runConfigTestApp(t, path, "--profile", "line2", "config", "show")
+	os.Unsetenv("NFC_CLI_HOST")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Profile: line2\n")
+	assert.Contains(t, out, "host: 10.0.0.3:3011 (NFC_CLI_HOST)\n")
+	assert.Contains(t, out, "ndef-type: url (profile line2)\n")
+
+	// flags which aren't set in command line are set from the profile
+	out, err = runConfigTestApp(t, path, "--profile", "line2", "ndef", "encode", "--url", "https://tagl.me")
+	assert.Nil(t, err)
+	assert.Equal(t, "D1 01 08 55 04 74 61 67 6C 2E 6D 65\n", out)
+	_, err = runConfigTestApp(t, path, "ndef", "encode", "--url", "https://tagl.me")
+	assert.NotNil(t, err)
+
+	_, err = runConfigTestApp(t, path, "--profile", "line3", "ndef", "encode", "--url", "https://tagl.me")
+	assert.EqualError(t, err, "Profile line3 is not found. Available profiles: line2")
+}
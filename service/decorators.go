@@ -1,10 +1,16 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"github.com/fatih/color"
 	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/dump"
 	"github.com/taglme/nfc-cli/models"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 	"github.com/urfave/cli/v2"
 	"log"
 	"os"
@@ -12,6 +18,8 @@ import (
 	"time"
 )
 
+const tagPollInterval = 300 * time.Millisecond
+
 func (s *appService) withWsConnect(ctx *cli.Context, cmdFunc func(*cli.Context) error) error {
 	s.cliStartedCb(s.host)
 
@@ -25,7 +33,10 @@ func (s *appService) withWsConnect(ctx *cli.Context, cmdFunc func(*cli.Context)
 		return nil
 	}
 
-	err := s.repository.RunWsConnection(s.eventHandler, s.errorHandler)
+	s.tagActivity = make(chan struct{}, 1)
+	// exit can be requested by events as soon as the connection is established
+	s.exitCh = make(chan struct{}, 1)
+	err := s.repository.RunWsConnection(s.eventHandler, s.errorHandler, s.resyncJobs)
 	if err != nil {
 		return errors.Wrap(err, "Can't establish the WS connection")
 	}
@@ -36,93 +47,343 @@ func (s *appService) withWsConnect(ctx *cli.Context, cmdFunc func(*cli.Context)
 		}
 	}()
 
+	// goroutines waiting for tags stop when the command exits
+	c1, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.sessionCtx = c1
+
 	err = s.withAdapter(ctx, cmdFunc)
 	if err != nil {
 		ctx.Done()
 		return err
 	}
 
-	c1, cancel := context.WithCancel(context.Background())
-	s.exitCh = make(chan struct{})
+	if s.deadline > 0 {
+		var cancelDeadline context.CancelFunc
+		c1, cancelDeadline = context.WithTimeout(c1, s.deadline)
+		defer cancelDeadline()
+	}
+	if s.idle > 0 {
+		go s.watchIdle(c1, cancel)
+	}
+
+	exited := make(chan struct{})
+	defer close(exited)
 	go func(ctx context.Context) {
-		for {
-			select {
-			case <-ctx.Done():
-				fmt.Println("\nReceived done. Deleting adapter jobs...")
-
-				err := s.repository.DeleteAdapterJobs(s.adapterId)
-				if err != nil {
-					log.Printf("Can't delete adapter jobs on exit: %s", err)
-				}
+		select {
+		case <-ctx.Done():
+		case <-exited:
+			return
+		}
+		// the context is canceled on return as well
+		select {
+		case <-exited:
+			return
+		default:
+		}
+
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			s.sessionMutex.Lock()
+			s.deadlineExceeded = true
+			s.sessionMutex.Unlock()
+			fmt.Printf("\nDeadline of %s exceeded. Deleting adapter jobs...\n", s.deadline)
+		case s.idleExceeded:
+			fmt.Printf("\nNo tags presented for %s. Deleting adapter jobs...\n", s.idle)
+		default:
+			fmt.Println("\nReceived done. Deleting adapter jobs...")
+		}
 
-				fmt.Println("Exiting...")
-				s.exitCh <- struct{}{}
-				return
-			default:
-				time.Sleep(50 * time.Millisecond)
+		for _, session := range s.sessions {
+			err := s.repository.DeleteAdapterJobs(session.adapter.AdapterID)
+			if err != nil {
+				log.Printf("Can't delete adapter jobs on exit: %s", err)
 			}
 		}
+
+		fmt.Println("Exiting...")
+		s.exit()
 	}(c1)
 
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt)
 	go func() {
 		for range signalCh {
+			s.sessionMutex.Lock()
+			s.interrupted = true
+			s.sessionMutex.Unlock()
 			cancel()
 			return
 		}
 	}()
 	<-s.exitCh
 
-	return err
+	// events and jobs of next tags can still be processed until the connection is stopped
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	if published, _ := s.runsProgress(); published > 0 {
+		s.printSummary()
+	}
+	err = s.flushJsonItems()
+	if err != nil {
+		return err
+	}
+
+	return s.runsError()
 }
 
+// watchIdle cancels the command when no tags are presented for idle timeout
+func (s *appService) watchIdle(ctx context.Context, cancel context.CancelFunc) {
+	timer := time.NewTimer(s.idle)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.tagActivity:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(s.idle)
+		case <-timer.C:
+			s.sessionMutex.Lock()
+			s.idleExceeded = true
+			s.sessionMutex.Unlock()
+			cancel()
+			return
+		}
+	}
+}
+
+// withAdapter runs the command for every adapter. Jobs of every adapter are tracked by separate session
 func (s *appService) withAdapter(ctx *cli.Context, cmdFunc func(*cli.Context) error) error {
-	adapters, err := s.repository.GetAdapters(false)
+	adapters, err := s.getCommandAdapters(ctx)
 	if err != nil {
 		return err
 	}
-	if s.adapter <= 0 || s.adapter > len(adapters) {
-		return errors.New("Can't find adapter with such index")
+
+	s.sessions = make([]*adapterSession, len(adapters))
+	for i, a := range adapters {
+		s.sessions[i] = &adapterSession{adapter: a}
 	}
+	s.repository.SetAdapterPrefix(s.multiAdapter())
 
-	s.adapterId = adapters[s.adapter-1].AdapterID
-	err = s.repository.DeleteAdapterJobs(s.adapterId)
-	if err != nil {
-		return errors.Wrap(err, "Can't delete adapter jobs: ")
+	// events of adapters which jobs are added are processed when jobs are added to all adapters
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	for i, session := range s.sessions {
+		s.session = session
+		err = s.repository.DeleteAdapterJobs(s.adapterId())
+		if err != nil {
+			return errors.Wrap(err, "Can't delete adapter jobs: ")
+		}
+
+		err = cmdFunc(ctx)
+		if err != nil && s.multiAdapter() {
+			// jobs are added to all adapters or none of them
+			for _, added := range s.sessions[:i] {
+				deleteErr := s.repository.DeleteAdapterJobs(added.adapter.AdapterID)
+				if deleteErr != nil {
+					log.Printf("Can't delete jobs of adapter %s: %s", added.adapter.Name, deleteErr)
+				}
+			}
+			return errors.Wrapf(err, "Adapter %s", session.adapter.Name)
+		}
+		if err != nil {
+			return err
+		}
 	}
 
-	return cmdFunc(ctx)
+	return nil
 }
 
 func (s *appService) eventHandler(e models.Event, data interface{}) {
-	s.cliStartedCb(s.host)
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	r := parseEventResource(data)
+	session := s.eventSession(r)
+	if session == nil {
+		return
+	}
+
+	switch e {
+	case models.EventTagDiscovery, models.EventRunStarted, models.EventRunSuccess, models.EventRunError:
+		select {
+		case s.tagActivity <- struct{}{}:
+		default:
+		}
+	}
+
+	switch e {
+	case models.EventJobSubmitted:
+		if len(r.JobID) > 0 {
+			session.setJob(r.JobID, false)
+			session.setRepeat(r.JobID, r.Repeat)
+		}
+	case models.EventJobFinished:
+		if finished, ok := session.jobs[r.JobID]; ok && !finished {
+			session.setJob(r.JobID, true)
+			if r.SuccessRuns < r.Repeat {
+				session.expireJob(r.Repeat - r.SuccessRuns)
+			}
+		}
+	case models.EventRunSuccess, models.EventRunError:
+		// runs finished while connection was lost are processed once
+		if len(r.RunID) > 0 && !session.addRun(r.RunID) {
+			return
+		}
+		session.recordRun(r, e == models.EventRunSuccess)
+	}
+
+	if (e == models.EventRunError || (e == models.EventRunSuccess && s.runSuccessHandler == nil)) && s.isJsonOutput() {
+		s.printJsonItem(data)
+	}
+
+	if e == models.EventRunError {
+		session.failed++
+	}
 
 	if e == models.EventRunSuccess {
-		s.ongoingJobs.left--
+		session.left--
+		session.succeeded++
+
+		if s.runSuccessHandler != nil {
+			s.runSuccessHandler(session, data)
+		}
+
+		if s.tagJob != nil && session.left > 0 {
+			go s.addTagJob(session, runTagUid(data))
+		}
 
 		if len(s.output) > 0 {
-			err := s.writeToFile(s.output, data)
+			write := s.writeToFile
+			if len(s.dumpFormat) > 0 && s.dumpFormat != dump.FormatRun {
+				write = s.writeDumpToFile
+			}
+			err := write(s.output, data)
 			if err != nil {
 				log.Println("Can't write to the file: ", err)
 			}
 		}
 	}
 
-	if (e == models.EventJobFinished) && (s.ongoingJobs.left < 1) {
-		err := s.repository.DeleteAdapterJobs(s.adapterId)
-		if err != nil {
-			log.Printf("Can't delete adapter jobs on exit: %s", err)
+	if e == models.EventJobFinished {
+		s.exitWhenFinished(session)
+	}
+}
+
+// exitWhenFinished deletes jobs of the session without runs left and exits when runs of all sessions are finished.
+// Session mutex should be locked
+func (s *appService) exitWhenFinished(session *adapterSession) {
+	if session.left > 0 {
+		return
+	}
+
+	err := s.repository.DeleteAdapterJobs(session.adapter.AdapterID)
+	if err != nil {
+		log.Printf("Can't delete adapter jobs on exit: %s", err)
+	}
+
+	if s.runsLeft() > 0 {
+		return
+	}
+	fmt.Println("Exiting...")
+	s.exit()
+}
+
+// failTag counts the run of the tag which job can't be added as failed. It returns false when no runs are left,
+// otherwise the job for the next tag should be added. Session mutex should be locked
+func (s *appService) failTag(session *adapterSession, uid []byte, err error) bool {
+	fmt.Println(color.RedString("Can't add job for tag % X: %s", uid, err))
+	session.failed++
+	session.left--
+	if session.left > 0 {
+		return true
+	}
+	s.exitWhenFinished(session)
+
+	return false
+}
+
+// addTagJob waits for the tag other than the previous one and adds the job for it to the session adapter
+func (s *appService) addTagJob(session *adapterSession, previous []byte) {
+	tag, err := s.waitForNewTag(s.sessionCtx, session.adapter.AdapterID, previous)
+	if err != nil {
+		return
+	}
+
+	err = s.tagJob(tag)
+	if err == nil {
+		return
+	}
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+	if s.failTag(session, tag.Uid, err) {
+		go s.addTagJob(session, tag.Uid)
+	}
+}
+
+// waitForNewTag polls tags in the field of the adapter until a tag other than the previous one appears.
+// Error is returned when the context is canceled
+func (s *appService) waitForNewTag(ctx context.Context, adapterId string, previous []byte) (apiModels.Tag, error) {
+	// errors are logged once until tags are received
+	lastErr := ""
+	for {
+		tags, err := s.repository.GetTags(adapterId, nil, false)
+		if err != nil && err.Error() != lastErr {
+			log.Println("Can't get tags: ", err)
+			lastErr = err.Error()
+		} else if err == nil {
+			lastErr = ""
+		}
+		for _, t := range tags {
+			if len(t.Uid) > 0 && !bytes.Equal(t.Uid, previous) {
+				return t, nil
+			}
 		}
 
-		fmt.Println("Exiting...")
-		s.exitCh <- struct{}{}
+		select {
+		case <-ctx.Done():
+			return apiModels.Tag{}, ctx.Err()
+		case <-time.After(tagPollInterval):
+		}
 	}
 }
 
+// runTagUid returns UID of the tag of job run received in the WS event
+func runTagUid(data interface{}) []byte {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+
+	var run apiModels.JobRunResource
+	if json.Unmarshal(encoded, &run) != nil {
+		return nil
+	}
+	uid, _ := base64.StdEncoding.DecodeString(run.Tag.Uid)
+
+	return uid
+}
+
 func (s *appService) errorHandler(err error) {
 	if err != nil {
 		fmt.Println("Server connection unexpectedly closed. Exiting...")
-		s.exitCh <- struct{}{}
+		s.sessionMutex.Lock()
+		s.connectionLost = true
+		s.sessionMutex.Unlock()
+		s.exit()
+	}
+}
+
+// exit requests the command to exit. The command waits for one request, so next requests are dropped
+// and senders never block
+func (s *appService) exit() {
+	select {
+	case s.exitCh <- struct{}{}:
+	default:
 	}
 }
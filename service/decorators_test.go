@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/taglme/nfc-cli/mock"
@@ -17,27 +18,28 @@ import (
 func Test_eventHandler(t *testing.T) {
 	rep := mock.NewRepositoryService(nil)
 	config := opts.Config{}
-	cbCliStarted := func(string) {}
+	// the repository connected to the events is used while events are processed
+	cbCliStarted := func(string) { t.Error("Repository is created by event handler") }
 	app := New(rep, cbCliStarted, config)
-	app.exitCh = make(chan struct{})
-	app.ongoingJobs.left = 2
-	app.ongoingJobs.published = 2
+	app.exitCh = make(chan struct{}, 1)
+	app.session.left = 2
+	app.session.published = 2
 
 	app.eventHandler(models.EventRunSuccess, "test data")
-	assert.Equal(t, 1, app.ongoingJobs.left)
+	assert.Equal(t, 1, app.session.left)
 
 	app.eventHandler(models.EventJobActivated, "test data")
-	assert.Equal(t, 1, app.ongoingJobs.left)
+	assert.Equal(t, 1, app.session.left)
 
 	app.eventHandler(models.EventJobFinished, "test data")
-	assert.Equal(t, 1, app.ongoingJobs.left)
+	assert.Equal(t, 1, app.session.left)
 
 	app.eventHandler(models.EventJobDeleted, "test data")
-	assert.Equal(t, 1, app.ongoingJobs.left)
+	assert.Equal(t, 1, app.session.left)
 
 	app.output = "decorators_test_file.json"
 	app.eventHandler(models.EventRunSuccess, "test data")
-	assert.Equal(t, 0, app.ongoingJobs.left)
+	assert.Equal(t, 0, app.session.left)
 	err := os.Remove(app.output)
 	assert.Nil(t, err)
 	app.output = ""
@@ -46,7 +48,7 @@ func Test_eventHandler(t *testing.T) {
 	go func(ctx context.Context) {
 		app.eventHandler(models.EventJobFinished, "test data")
 	}(c1)
-	assert.Equal(t, 0, app.ongoingJobs.left)
+	assert.Equal(t, 0, app.session.left)
 	time.Sleep(time.Duration(1000) * time.Millisecond)
 	cancel()
 	select {
@@ -64,7 +66,7 @@ func Test_errorHandler(t *testing.T) {
 	config := opts.Config{}
 	cbCliStarted := func(string) {}
 	app := New(rep, cbCliStarted, config)
-	app.exitCh = make(chan struct{})
+	app.exitCh = make(chan struct{}, 1)
 
 	c1, cancel := context.WithCancel(context.Background())
 	go func(ctx context.Context) {
@@ -82,6 +84,20 @@ func Test_errorHandler(t *testing.T) {
 	close(app.exitCh)
 }
 
+func Test_exit(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+
+	// exit is requested by finished jobs and lost connection while the command waits for one request
+	app.exit()
+	out := captureStdout(t, func() {
+		app.errorHandler(errors.New("websocket closed on server side"))
+	})
+	assert.Equal(t, "Server connection unexpectedly closed. Exiting...\n", out)
+	assert.True(t, app.connectionLost)
+	assert.Equal(t, 1, len(app.exitCh))
+}
+
 func Test_withAdapter(t *testing.T) {
 	rep := mock.NewRepositoryService(nil)
 	config := opts.Config{}
@@ -95,15 +111,57 @@ func Test_withAdapter(t *testing.T) {
 	}
 
 	ctx := cli.Context{}
-	app.adapter = -1
+	app.adapter = "-1"
 	err := app.withAdapter(&ctx, f)
 	assert.EqualError(t, err, "Can't find adapter with such index")
 
-	app.adapter = 25
+	app.adapter = "25"
 	err = app.withAdapter(&ctx, f)
 	assert.EqualError(t, err, "Can't find adapter with such index")
 
-	app.adapter = 1
+	app.adapter = "1"
 	err = app.withAdapter(&ctx, f)
 	assert.Nil(t, err)
 }
+
+func Test_withWsConnect_timeouts(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.adapter = "1"
+	ctx := cli.NewContext(nil, flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	// jobs are never finished
+	f := func(*cli.Context) error {
+		app.session.published, app.session.left = 1, 1
+		return nil
+	}
+
+	app.idle = 100 * time.Millisecond
+	out := captureStdout(t, func() {
+		err := app.withWsConnect(ctx, f)
+		assert.EqualError(t, err, "No tags presented for 100ms")
+		assert.Equal(t, models.ExitTimeout, ExitCode(err))
+	})
+	assert.Contains(t, out, "No tags presented for 100ms. Deleting adapter jobs...\nExiting...\n")
+
+	app.idle, app.idleExceeded = 0, false
+	app.deadline = 100 * time.Millisecond
+	out = captureStdout(t, func() {
+		err := app.withWsConnect(ctx, f)
+		assert.EqualError(t, err, "Deadline of 100ms exceeded")
+		assert.Equal(t, models.ExitTimeout, ExitCode(err))
+	})
+	assert.Contains(t, out, "Deadline of 100ms exceeded. Deleting adapter jobs...\nExiting...\n")
+}
+
+func Test_waitForNewTag(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+
+	tag, err := app.waitForNewTag(context.Background(), "mocked adapter id", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x04, 0xA2, 0x3B, 0x12}, tag.Uid)
+
+	// the only tag in the field is the previous one
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = app.waitForNewTag(ctx, "mocked adapter id", tag.Uid)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
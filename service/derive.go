@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/dump"
+	"github.com/taglme/nfc-cli/kdf"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/utils"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// masterKeyEnv is environment variable with HEX master key of derived passwords
+const masterKeyEnv = "NFC_CLI_MASTER_KEY"
+
+// tagJobFunc adds job with params. The tag in the field is set for jobs added for every tag
+type tagJobFunc = func(p models.GenericJobParams, tag apiModels.Tag) (*apiModels.NewJob, error)
+
+// addTagJobs adds the job with repeat, or jobs for every tag one by one when the job depends on the tag UID.
+// Password of auth step is derived from the tag UID with deriveAuth
+func (s *appService) addTagJobs(ctx *cli.Context, p models.GenericJobParams, deriveAuth, perTag bool, add tagJobFunc) (interface{}, error) {
+	s.session.published = s.repeat
+	s.session.left = s.repeat
+
+	if !deriveAuth && !perTag {
+		nj, err := add(p, apiModels.Tag{})
+		if nj == nil {
+			return nil, err
+		}
+		return nj, err
+	}
+
+	if p.Export {
+		return nil, validationError(errors.New("Jobs with derived passwords can't be exported as they are added for every tag"))
+	}
+	if s.multiAdapter() {
+		return nil, validationError(errors.New("Jobs with derived passwords can't run on several adapters as they are added for every tag"))
+	}
+	if deriveAuth && len(p.Auth) > 0 {
+		return nil, validationError(errors.New("Flags auth and auth-derive can't be used together"))
+	}
+
+	var err error
+	s.derivePassword, err = parseDeriveFlags(ctx)
+	if err != nil {
+		return nil, validationError(err)
+	}
+
+	p.Repeat = 1
+	s.tagJob = func(tag apiModels.Tag) error {
+		tagParams := p
+		if deriveAuth {
+			pwd, err := s.derivePassword(tag.Uid)
+			if err != nil {
+				return err
+			}
+			tagParams.Auth = pwd.Pwd
+		}
+
+		_, err := add(tagParams, tag)
+		return err
+	}
+
+	fmt.Println("Waiting for tag...")
+	go s.addTagJob(s.session, nil)
+
+	return nil, nil
+}
+
+// parseDeriveFlags returns function which derives password of the tag. Master key is read from key-file or environment
+// variable, so it doesn't get into shell history
+func parseDeriveFlags(ctx *cli.Context) (func(uid []byte) (kdf.Password, error), error) {
+	var key []byte
+	var err error
+	if filename := ctx.String(models.FlagKeyFile); len(filename) > 0 {
+		key, err = readMasterKey(filename)
+	} else if env := os.Getenv(masterKeyEnv); len(env) > 0 {
+		key, err = utils.ParseHexString(env)
+		if err != nil {
+			err = errors.Wrapf(err, "Can't parse %s. It should be HEX string", masterKeyEnv)
+		}
+	} else {
+		err = errors.New(fmt.Sprintf("Master key should be set with key-file flag or %s environment variable", masterKeyEnv))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	alg := ctx.String(models.FlagKdf)
+	_, err = kdf.Derive(alg, key, []byte{0x00})
+	if err != nil {
+		return nil, err
+	}
+
+	return func(uid []byte) (kdf.Password, error) {
+		return kdf.Derive(alg, key, uid)
+	}, nil
+}
+
+func readMasterKey(filename string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read master key file")
+	}
+
+	key, err := utils.ParseHexString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse master key file. It should contain HEX string")
+	}
+
+	return key, nil
+}
+
+// packWriteCommands returns tag WRITE command of PACK page. Nothing is written when the page of the tag product is unknown
+func packWriteCommands(product string, pack []byte) [][]byte {
+	page, ok := dump.PackPage(product)
+	if !ok {
+		return nil
+	}
+
+	return [][]byte{append([]byte{0xA2, byte(page)}, pack[0], pack[1], 0x00, 0x00)}
+}
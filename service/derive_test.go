@@ -0,0 +1,87 @@
+package service
+
+import (
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/kdf"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func newDeriveContext(t *testing.T, args []string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(models.FlagKdf, string(kdf.AlgorithmHmacSha256), "")
+	set.String(models.FlagKeyFile, "", "")
+	err := set.Parse(args)
+	assert.Nil(t, err)
+
+	return cli.NewContext(nil, set, nil)
+}
+
+func Test_parseDeriveFlags(t *testing.T) {
+	os.Unsetenv(masterKeyEnv)
+	_, err := parseDeriveFlags(newDeriveContext(t, []string{}))
+	assert.EqualError(t, err, "Master key should be set with key-file flag or NFC_CLI_MASTER_KEY environment variable")
+
+	os.Setenv(masterKeyEnv, "00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F")
+	defer os.Unsetenv(masterKeyEnv)
+	derive, err := parseDeriveFlags(newDeriveContext(t, []string{"-kdf", "aes-cmac"}))
+	assert.Nil(t, err)
+	uid := []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}
+	pwd, err := derive(uid)
+	assert.Nil(t, err)
+	expected, _ := kdf.Derive(kdf.AlgorithmAesCmac, []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}, uid)
+	assert.Equal(t, expected, pwd)
+
+	_, err = parseDeriveFlags(newDeriveContext(t, []string{"-kdf", "md5"}))
+	assert.EqualError(t, err, "Unknown key derivation algorithm md5. Can be one of: hmac-sha256, aes-cmac")
+
+	err = ioutil.WriteFile("derive_test.key", []byte("0A 0B 0C\n"), 0600)
+	assert.Nil(t, err)
+	defer os.Remove("derive_test.key")
+	derive, err = parseDeriveFlags(newDeriveContext(t, []string{"-key-file", "derive_test.key"}))
+	assert.Nil(t, err)
+	pwd, err = derive(uid)
+	assert.Nil(t, err)
+	expected, _ = kdf.Derive(kdf.AlgorithmHmacSha256, []byte{0x0A, 0x0B, 0x0C}, uid)
+	assert.Equal(t, expected, pwd)
+
+	_, err = parseDeriveFlags(newDeriveContext(t, []string{"-key-file", "derive_test.missing"}))
+	assert.NotNil(t, err)
+}
+
+func Test_addTagJobs(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	app := New(rep, func(string) {}, opts.Config{})
+	app.repeat = 3
+
+	added := 0
+	add := func(p models.GenericJobParams, tag apiModels.Tag) (*apiModels.NewJob, error) {
+		added++
+		return &apiModels.NewJob{JobName: "test"}, nil
+	}
+
+	nj, err := app.addTagJobs(newDeriveContext(t, []string{}), models.GenericJobParams{Repeat: 3}, false, false, add)
+	assert.Nil(t, err)
+	assert.NotNil(t, nj)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 3, app.session.left)
+	assert.Nil(t, app.tagJob)
+
+	_, err = app.addTagJobs(newDeriveContext(t, []string{}), models.GenericJobParams{Repeat: 3, Export: true}, true, false, add)
+	assert.EqualError(t, err, "Jobs with derived passwords can't be exported as they are added for every tag")
+
+	_, err = app.addTagJobs(newDeriveContext(t, []string{}), models.GenericJobParams{Repeat: 3, Auth: []byte{0x01}}, true, false, add)
+	assert.EqualError(t, err, "Flags auth and auth-derive can't be used together")
+}
+
+func Test_packWriteCommands(t *testing.T) {
+	assert.Equal(t, [][]byte{{0xA2, 0x2C, 0x12, 0x34, 0x00, 0x00}}, packWriteCommands("NTAG213", []byte{0x12, 0x34}))
+	assert.Nil(t, packWriteCommands("Mifare Classic 1K", []byte{0x12, 0x34}))
+}
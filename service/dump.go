@@ -0,0 +1,427 @@
+package service
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/dump"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-cli/utils"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"log"
+	"strings"
+)
+
+func (s *appService) cmdDumpAnalyze(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("Dump file should be specified. It is written by the dump command with output flag")
+	}
+
+	dumps, err := dump.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if s.isJsonOutput() {
+		items := make([]interface{}, len(dumps))
+		for i, d := range dumps {
+			items[i] = dumpAnalysisOutput(d)
+		}
+
+		return s.printJsonList(items, nil)
+	}
+
+	for i, d := range dumps {
+		if len(dumps) > 1 {
+			fmt.Printf("Dump %d of %d\n", i+1, len(dumps))
+		}
+		printDumpAnalysis(d)
+		fmt.Println()
+	}
+
+	return nil
+}
+
+func (s *appService) cmdDumpExport(ctx *cli.Context) error {
+	if ctx.Args().Len() != 1 {
+		return errors.New("Dump file should be specified")
+	}
+	if len(s.output) == 0 {
+		return errors.New("Output file should be set with output flag")
+	}
+
+	// converted dumps can't be written as job runs, so format is detected by output file extension without the flag
+	format := dump.FormatFromFilename(s.output)
+	if f := ctx.String(models.FlagDumpFormat); len(f) > 0 {
+		var err error
+		format, err = getDumpFormat(f)
+		if err != nil {
+			return err
+		}
+	}
+	if format == dump.FormatRun {
+		return errors.New("Dump can't be exported as job run. Use dump-format flag or output file extension to set the format")
+	}
+
+	dumps, err := dump.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+
+	for i, d := range dumps {
+		filename := s.output
+		if len(dumps) > 1 {
+			filename = numberedFilename(filename, i+1)
+		}
+
+		err = writeDump(filename, d, format)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Dump of tag % X is written to %s in %s format\n", d.Uid, filename, format)
+	}
+
+	return nil
+}
+
+// getDumpFormat validates dump-format flag value. Dumps are written as job runs when it is not set
+func getDumpFormat(format string) (dump.Format, error) {
+	if len(format) == 0 {
+		return dump.FormatRun, nil
+	}
+
+	for _, f := range dump.Formats {
+		if format == f {
+			return f, nil
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("Wrong dump-format flag value. Can be one of: %s", strings.Join(dump.Formats, ", ")))
+}
+
+func (s *appService) cmdDumpDiff(ctx *cli.Context) error {
+	if ctx.Args().Len() != 2 {
+		return errors.New("Two dump files should be specified")
+	}
+
+	var dumps [2]dump.Dump
+	for i, filename := range ctx.Args().Slice() {
+		d, err := dump.ReadSingleFile(filename)
+		if err != nil {
+			return err
+		}
+		dumps[i] = d
+	}
+
+	if s.isJsonOutput() {
+		return s.printJson(dumpDiffOutput(ctx.Args().Get(0), dumps[0], ctx.Args().Get(1), dumps[1]))
+	}
+
+	printDumpDiff(ctx.Args().Get(0), dumps[0], ctx.Args().Get(1), dumps[1])
+
+	return nil
+}
+
+// dumpRunHandler handles successful dump runs when dump command is called with analyze or diff flags.
+// Dumped tag is compared with the reference dump when it is set
+func (s *appService) dumpRunHandler(analyze bool, referenceFile string, reference *dump.Dump) func(*adapterSession, interface{}) {
+	return func(_ *adapterSession, data interface{}) {
+		d, err := dump.FromRunData(data)
+		if err != nil {
+			log.Println("Can't process dump: ", err)
+			return
+		}
+
+		if analyze {
+			if s.isJsonOutput() {
+				s.printJsonItem(dumpAnalysisOutput(d))
+			} else {
+				printDumpAnalysis(d)
+			}
+		}
+
+		if reference != nil {
+			if s.isJsonOutput() {
+				s.printJsonItem(dumpDiffOutput(referenceFile, *reference, "tag", d))
+			} else {
+				printDumpDiff(referenceFile, *reference, "tag", d)
+			}
+		}
+	}
+}
+
+// decodeDumpNdef extracts and decodes NDEF message from the data area of the dump
+func decodeDumpNdef(l dump.Layout) ([]ndef.NdefPayload, []ndef.Record, error) {
+	message, err := l.NdefMessage()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ndef.Decode(message)
+}
+
+func printDumpAnalysis(d dump.Dump) {
+	l := dump.Analyze(d)
+
+	fmt.Printf("Tag %s (%s), UID % X\n", l.Model, l.Family, d.Uid)
+	for _, w := range l.Warnings {
+		fmt.Println("Warning:", w)
+	}
+
+	width := d.PageSize()*3 - 1
+	fmt.Printf("%4s  %-*s  %s\n", "Page", width, "Data", "Region")
+	for _, p := range d.Pages {
+		name := ""
+		var notes []string
+		if r, ok := l.Region(p.Number); ok && r.Page == p.Number {
+			name = r.Name
+			notes = r.Notes
+		}
+
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%4d  %-*s  %s", p.Number, width, fmt.Sprintf("% X", p.Data), name), " "))
+		for _, n := range notes {
+			fmt.Printf("%4s  %-*s  - %s\n", "", width, "", n)
+		}
+	}
+
+	payloads, records, err := decodeDumpNdef(l)
+	if err != nil {
+		fmt.Printf("NDEF message: %s\n", err)
+		return
+	}
+
+	fmt.Println("NDEF message:")
+	printNdefRecords(payloads, records)
+}
+
+func dumpAnalysisOutput(d dump.Dump) models.DumpAnalysisOutput {
+	l := dump.Analyze(d)
+	res := models.DumpAnalysisOutput{
+		Family:   l.Family,
+		Model:    l.Model,
+		Uid:      fmt.Sprintf("% X", d.Uid),
+		Warnings: l.Warnings,
+	}
+
+	for _, p := range d.Pages {
+		page := models.DumpPageOutput{Page: p.Number, Data: fmt.Sprintf("% X", p.Data)}
+		if r, ok := l.Region(p.Number); ok {
+			page.Kind = r.Kind
+			page.Region = r.Name
+			if r.Page == p.Number {
+				page.Notes = r.Notes
+			}
+		}
+		res.Pages = append(res.Pages, page)
+	}
+
+	payloads, _, err := decodeDumpNdef(l)
+	if err != nil {
+		res.NdefError = err.Error()
+		return res
+	}
+	res.NdefRecords = ndefPayloadsToMaps(payloads)
+
+	return res
+}
+
+// dumpDiffKinds is an order of memory region kinds in the diff summary
+var dumpDiffKinds = []dump.Kind{dump.KindUid, dump.KindLock, dump.KindConfig, dump.KindUser}
+
+func printDumpDiff(nameA string, a dump.Dump, nameB string, b dump.Dump) {
+	diffs := dump.Diff(a, b)
+
+	fmt.Printf("A: %s, UID % X\n", nameA, a.Uid)
+	fmt.Printf("B: %s, UID % X\n", nameB, b.Uid)
+	if len(diffs) == 0 {
+		fmt.Println(color.GreenString("Dumps are equal"))
+		return
+	}
+
+	size := a.PageSize()
+	if b.PageSize() > size {
+		size = b.PageSize()
+	}
+	width := size*3 - 1
+
+	fmt.Printf("%4s  %-*s  %-*s  %s\n", "Page", width, "A", width, "B", "Region")
+	summary := make(map[dump.Kind]int)
+	for _, d := range diffs {
+		summary[d.Kind]++
+
+		kind := color.YellowString("[%s]", d.Kind)
+		if d.Kind != dump.KindUser {
+			kind = color.RedString("[%s]", d.Kind)
+		}
+		fmt.Printf("%4d  %s  %s  %s %s\n", d.Page, formatDiffBytes(d.A, d.Changed, width), formatDiffBytes(d.B, d.Changed, width), kind, d.Region)
+	}
+
+	var counts []string
+	for _, k := range dumpDiffKinds {
+		counts = append(counts, fmt.Sprintf("%s %d", k, summary[k]))
+	}
+	fmt.Printf("Changed pages: %d (%s)\n", len(diffs), strings.Join(counts, ", "))
+	if len(diffs) > summary[dump.KindUser] {
+		fmt.Println(color.RedString("UID, lock or config pages differ"))
+	}
+}
+
+// formatDiffBytes prints page data with highlighted changed bytes padded to the width
+func formatDiffBytes(data []byte, changed []int, width int) string {
+	if data == nil {
+		return fmt.Sprintf("%-*s", width, "missing")
+	}
+
+	isChanged := make(map[int]bool, len(changed))
+	for _, i := range changed {
+		isChanged[i] = true
+	}
+
+	parts := make([]string, len(data))
+	for i, b := range data {
+		parts[i] = fmt.Sprintf("%02X", b)
+		if isChanged[i] {
+			parts[i] = color.New(color.FgRed, color.Bold).Sprint(parts[i])
+		}
+	}
+
+	return strings.Join(parts, " ") + strings.Repeat(" ", width-len(data)*3+1)
+}
+
+func dumpDiffOutput(nameA string, a dump.Dump, nameB string, b dump.Dump) models.DumpDiffOutput {
+	diffs := dump.Diff(a, b)
+	res := models.DumpDiffOutput{
+		A:       nameA,
+		B:       nameB,
+		UidA:    fmt.Sprintf("% X", a.Uid),
+		UidB:    fmt.Sprintf("% X", b.Uid),
+		Equal:   len(diffs) == 0,
+		Summary: make(map[string]int, len(dumpDiffKinds)),
+	}
+	for _, k := range dumpDiffKinds {
+		res.Summary[k] = 0
+	}
+
+	for _, d := range diffs {
+		res.Summary[d.Kind]++
+	}
+	res.Pages = dumpPageDiffOutputs(diffs)
+
+	return res
+}
+
+func dumpPageDiffOutputs(diffs []dump.PageDiff) []models.DumpPageDiffOutput {
+	res := []models.DumpPageDiffOutput{}
+	for _, d := range diffs {
+		res = append(res, models.DumpPageDiffOutput{
+			Page:    d.Page,
+			Kind:    d.Kind,
+			Region:  d.Region,
+			A:       fmt.Sprintf("% X", d.A),
+			B:       fmt.Sprintf("% X", d.B),
+			Changed: d.Changed,
+		})
+	}
+
+	return res
+}
+
+func (s *appService) cmdRestore(ctx *cli.Context) error {
+	auth, err := utils.ParseHexString(s.auth)
+	if err != nil {
+		return validationError(errors.Wrap(err, "Can't parse auth string. It should be HEX string i.e. \"03 AD F3 41\""))
+	}
+	export := ctx.Bool(models.FlagExport)
+
+	var allow []dump.Kind
+	for _, k := range ctx.StringSlice(models.FlagAllow) {
+		if k != dump.KindUid && k != dump.KindLock && k != dump.KindConfig {
+			return validationError(errors.New("Wrong allow flag value. Can be \"uid\", \"lock\" or \"config\"."))
+		}
+		allow = append(allow, k)
+	}
+
+	d, err := dump.ReadSingleFile(ctx.String(models.FlagFrom))
+	if err != nil {
+		return validationError(err)
+	}
+
+	pages, skipped, err := dump.RestorePages(d, allow...)
+	if err != nil {
+		return validationError(err)
+	}
+	if len(pages) == 0 {
+		return validationError(errors.New("Dump doesn't contain pages to restore"))
+	}
+
+	skippedNumbers := make([]int, len(skipped))
+	for i, p := range skipped {
+		skippedNumbers[i] = p.Number
+	}
+	fmt.Printf("Restoring %d pages of tag % X. Skipped pages: %s\n", len(pages), d.Uid, dump.FormatPages(skippedNumbers))
+
+	commands := make([][]byte, len(pages))
+	for i, p := range pages {
+		commands[i] = dump.WriteCommand(p)
+	}
+
+	p := models.GenericJobParams{
+		AdapterId: s.adapterId(),
+		Repeat:    s.repeat,
+		Expire:    s.timeout,
+		Auth:      auth,
+		Export:    export,
+		JobName:   s.jobName,
+	}
+
+	var nj interface{}
+	nj, err = s.addTagJobs(ctx, p, ctx.Bool(models.FlagAuthDerive), false, func(p models.GenericJobParams, _ apiModels.Tag) (*apiModels.NewJob, error) {
+		_, nj, err := s.repository.AddRestoreJob(p, commands)
+		return nj, err
+	})
+	if err != nil {
+		return err
+	}
+	s.runSuccessHandler = s.restoreRunHandler(pages)
+
+	return s.exportData(export, nj)
+}
+
+// restoreRunHandler verifies restored pages with the dump read back at the end of the restore job
+func (s *appService) restoreRunHandler(pages []dump.Page) func(*adapterSession, interface{}) {
+	return func(_ *adapterSession, data interface{}) {
+		d, err := dump.FromRunData(data)
+		if err != nil {
+			log.Println("Can't verify restored tag: ", err)
+			return
+		}
+
+		mismatches := dump.Verify(pages, d)
+		if len(mismatches) > 0 {
+			s.failedRuns++
+		}
+		if s.isJsonOutput() {
+			s.printJsonItem(models.RestoreVerificationOutput{
+				Uid:        fmt.Sprintf("% X", d.Uid),
+				Pages:      len(pages),
+				Verified:   len(mismatches) == 0,
+				Mismatches: dumpPageDiffOutputs(mismatches),
+			})
+			return
+		}
+
+		if len(mismatches) == 0 {
+			fmt.Println(color.GreenString("Verification: %d pages of tag % X are restored", len(pages), d.Uid))
+			return
+		}
+
+		fmt.Println(color.RedString("Verification failed: %d of %d pages of tag % X differ from the dump", len(mismatches), len(pages), d.Uid))
+		width := d.PageSize()*3 - 1
+		fmt.Printf("%4s  %-*s  %-*s  %s\n", "Page", width, "Dump", width, "Tag", "Region")
+		for _, m := range mismatches {
+			fmt.Printf("%4d  %s  %s  %s\n", m.Page, formatDiffBytes(m.A, m.Changed, width), formatDiffBytes(m.B, m.Changed, width), m.Region)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+const dumpTestFile = "dump_test_file.json"
+
+func writeDumpTestFile(t *testing.T) {
+	pages := []string{"04 A2 B3 9D", "C4 D5 E6 F7", "00 48 00 00", "E1 10 06 00", "03 0C D1 01", "08 55 04 74", "61 67 6C 2E", "6D 65 FE 00"}
+	var memoryDump []map[string]string
+	for i := 0; i < 16; i++ {
+		data := "00 00 00 00"
+		if i < len(pages) {
+			data = pages[i]
+		}
+		memoryDump = append(memoryDump, map[string]string{"page": fmt.Sprintf("%02X", i), "data": data})
+	}
+
+	run, err := json.Marshal(map[string]interface{}{
+		"tag":     map[string]string{"uid": "BKKzxNXm9w==", "product": "MIFARE Ultralight"},
+		"results": []interface{}{map[string]interface{}{"command": "get_dump", "output": map[string]interface{}{"memory_dump": memoryDump}}},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(dumpTestFile, run, 0644))
+}
+
+func Test_cmdDumpAnalyze(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, opts.Config{})
+	writeDumpTestFile(t)
+	defer os.Remove(dumpTestFile)
+
+	out, err := startWithStdout(t, app, models.CommandDump, models.CommandAnalyze, dumpTestFile)
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Tag MIFARE Ultralight (ntag/ultralight), UID 04 A2 B3 C4 D5 E6 F7\n")
+	assert.Contains(t, out, "   3  E1 10 06 00  Capability container\n")
+	assert.Contains(t, out, "NDEF message:\nRecord 1: url (flags MB ME SR, TNF 1, type \"U\", payload 8 bytes)\n   url: https://tagl.me\n")
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandDump, models.CommandAnalyze, dumpTestFile)
+	assert.Nil(t, err)
+	var analysis []models.DumpAnalysisOutput
+	assert.Nil(t, json.Unmarshal([]byte(out), &analysis))
+	assert.Equal(t, "lock", analysis[0].Pages[2].Kind)
+	assert.Equal(t, []map[string]string{{"ndef-type": "url", "url": "https://tagl.me"}}, analysis[0].NdefRecords)
+
+	_, err = startWithStdout(t, app, models.CommandDump, models.CommandAnalyze)
+	assert.EqualError(t, err, "Dump file should be specified. It is written by the dump command with output flag")
+}
+
+func Test_cmdDumpExport(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, opts.Config{})
+	writeDumpTestFile(t)
+	defer os.Remove(dumpTestFile)
+
+	_, err := startWithStdout(t, app, models.CommandDump, models.CommandExport, "--"+models.FlagOutput, "dump_test_file.bin", dumpTestFile)
+	assert.Nil(t, err)
+	defer os.Remove("dump_test_file.bin")
+	data, err := ioutil.ReadFile("dump_test_file.bin")
+	assert.Nil(t, err)
+	assert.Len(t, data, 64)
+	assert.Equal(t, []byte{0x04, 0xA2, 0xB3, 0x9D}, data[:4])
+
+	out, err := startWithStdout(t, app, models.CommandDump, models.CommandAnalyze, "dump_test_file.bin")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "url: https://tagl.me\n")
+
+	_, err = startWithStdout(t, app, models.CommandDump, models.CommandExport, "--"+models.FlagOutput, "dump_test_file.bin", "--"+models.FlagDumpFormat, "csv", dumpTestFile)
+	assert.EqualError(t, err, "Wrong dump-format flag value. Can be one of: json, bin, hex, eml, proxmark, flipper")
+
+	_, err = startWithStdout(t, app, models.CommandDump, models.CommandExport, "--"+models.FlagOutput, "dump_test_file.json", dumpTestFile)
+	assert.EqualError(t, err, "Dump can't be exported as job run. Use dump-format flag or output file extension to set the format")
+}
+
+func Test_cmdDumpDiff(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, opts.Config{})
+	writeDumpTestFile(t)
+	defer os.Remove(dumpTestFile)
+
+	_, err := startWithStdout(t, app, models.CommandDump, models.CommandExport, "--"+models.FlagOutput, "dump_test_file.hex", dumpTestFile)
+	assert.Nil(t, err)
+	defer os.Remove("dump_test_file.hex")
+
+	out, err := startWithStdout(t, app, models.CommandDump, models.CommandDiff, dumpTestFile, "dump_test_file.hex")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Dumps are equal")
+
+	data, err := ioutil.ReadFile("dump_test_file.hex")
+	assert.Nil(t, err)
+	data = []byte(strings.Replace(string(data), "00 48 00 00", "00 48 F0 FF", 1))
+	assert.Nil(t, ioutil.WriteFile("dump_test_file.hex", data, 0644))
+
+	out, err = startWithStdout(t, app, models.CommandDump, models.CommandDiff, dumpTestFile, "dump_test_file.hex")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Changed pages: 1 (uid 0, lock 1, config 0, user 0)\n")
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandDump, models.CommandDiff, dumpTestFile, "dump_test_file.hex")
+	assert.Nil(t, err)
+	var diff models.DumpDiffOutput
+	assert.Nil(t, json.Unmarshal([]byte(out), &diff))
+	assert.Equal(t, []models.DumpPageDiffOutput{{Page: 2, Kind: "lock", Region: "BCC1, internal, static lock bytes", A: "00 48 00 00", B: "00 48 F0 FF", Changed: []int{2, 3}}}, diff.Pages)
+
+	_, err = startWithStdout(t, app, models.CommandDump, models.CommandDiff, dumpTestFile)
+	assert.EqualError(t, err, "Two dump files should be specified")
+}
+
+func Test_cmdRestore(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, opts.Config{})
+	writeDumpTestFile(t)
+	defer os.Remove(dumpTestFile)
+
+	out, err := startWithStdout(t, app, models.CommandRestore, "--"+models.FlagFrom, dumpTestFile, "--"+models.FlagExport, "--"+models.FlagOutput, "cmd_test_file.json")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Restoring 12 pages of tag 04 A2 B3 C4 D5 E6 F7. Skipped pages: 0-3\n")
+	assert.Nil(t, os.Remove("cmd_test_file.json"))
+
+	out, err = startWithStdout(t, app, models.CommandRestore, "--"+models.FlagFrom, dumpTestFile, "--"+models.FlagAllow, "lock", "--"+models.FlagAllow, "config", "--"+models.FlagExport, "--"+models.FlagOutput, "cmd_test_file.json")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Restoring 14 pages of tag 04 A2 B3 C4 D5 E6 F7. Skipped pages: 0-1\n")
+	assert.Nil(t, os.Remove("cmd_test_file.json"))
+
+	_, err = startWithStdout(t, app, models.CommandRestore, "--"+models.FlagFrom, dumpTestFile, "--"+models.FlagAllow, "pwd", "--"+models.FlagExport)
+	assert.EqualError(t, err, "Wrong allow flag value. Can be \"uid\", \"lock\" or \"config\".")
+}
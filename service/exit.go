@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/jobfile"
+	"github.com/taglme/nfc-cli/models"
+	"net"
+)
+
+// exitError sets exit code of the process for the error
+type exitError struct {
+	code models.ExitCode
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Cause() error {
+	return e.err
+}
+
+func withExitCode(code models.ExitCode, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// ExitCode returns exit code of the process for the error returned by the command
+func ExitCode(err error) models.ExitCode {
+	if err == nil {
+		return models.ExitSuccess
+	}
+
+	for e := err; e != nil; {
+		switch c := e.(type) {
+		case *exitError:
+			return c.code
+		case *ndefFieldError, *jobfile.Error:
+			return models.ExitValidation
+		case net.Error:
+			return models.ExitUnreachable
+		}
+
+		causer, ok := e.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		e = causer.Cause()
+	}
+
+	return models.ExitError
+}
+
+// validationError marks errors of command flags and files, so the command exits with validation code
+func validationError(err error) error {
+	return withExitCode(models.ExitValidation, err)
+}
+
+// runsError returns error of finished jobs when runs failed or expired, or the command is interrupted
+func (s *appService) runsError() error {
+	if s.interrupted {
+		return withExitCode(models.ExitInterrupted, errors.New("Interrupted"))
+	}
+	if s.connectionLost {
+		return withExitCode(models.ExitUnreachable, errors.New("Server connection is lost"))
+	}
+
+	if s.deadlineExceeded {
+		return withExitCode(models.ExitTimeout, errors.New(fmt.Sprintf("Deadline of %s exceeded", s.deadline)))
+	}
+	if s.idleExceeded {
+		return withExitCode(models.ExitTimeout, errors.New(fmt.Sprintf("No tags presented for %s", s.idle)))
+	}
+
+	t := s.runsTotal()
+	if t.expired > 0 {
+		return withExitCode(models.ExitTimeout, errors.New(fmt.Sprintf("Jobs expired with %d runs left", t.expired)))
+	}
+	if s.misWrittenTags > 0 {
+		return withExitCode(models.ExitRunsFailed, errors.New(fmt.Sprintf("%d tags are mis-written with records rendered for other tags", s.misWrittenTags)))
+	}
+	if s.failedRuns > 0 {
+		return withExitCode(models.ExitRunsFailed, errors.New(fmt.Sprintf("Verification failed for %d tags", s.failedRuns)))
+	}
+	if t.failed > 0 {
+		return withExitCode(models.ExitRunsFailed, errors.New(fmt.Sprintf("%d runs failed", t.failed)))
+	}
+
+	return nil
+}
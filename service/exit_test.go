@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"net"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, models.ExitSuccess, ExitCode(nil))
+	assert.Equal(t, models.ExitError, ExitCode(errors.New("Can't read the file")))
+
+	_, err := validateNdefRecordPayloadUrl("tagl.me")
+	assert.Equal(t, models.ExitValidation, ExitCode(errors.Wrap(err, "Record 1")))
+	assert.Equal(t, models.ExitValidation, ExitCode(validationError(errors.New("Batch file is empty"))))
+
+	_, err = net.Dial("tcp", "127.0.0.1:0")
+	assert.Equal(t, models.ExitUnreachable, ExitCode(errors.Wrap(err, "Can't get adapters")))
+
+	assert.Equal(t, models.ExitInterrupted, ExitCode(withExitCode(models.ExitInterrupted, errors.New("Interrupted"))))
+}
+
+// rejectedJobRepository is a server which fails to add jobs
+type rejectedJobRepository struct {
+	*mock.MockedRepositoryService
+}
+
+func (r rejectedJobRepository) AddGenericJob(p models.GenericJobParams) (*apiModels.Job, *apiModels.NewJob, error) {
+	return nil, nil, errors.New("Server responded with an error: Internal server error")
+}
+
+func TestExitCode_command(t *testing.T) {
+	app := New(rejectedJobRepository{mock.NewRepositoryService(nil)}, func(string) {}, opts.Config{})
+
+	_, err := startWithStdout(t, app, models.CommandRead, "--"+models.FlagAuth, "not hex")
+	assert.Equal(t, models.ExitValidation, ExitCode(err))
+
+	// errors of the server aren't validation errors
+	_, err = startWithStdout(t, app, models.CommandRead)
+	assert.EqualError(t, err, "Server responded with an error: Internal server error")
+	assert.Equal(t, models.ExitError, ExitCode(err))
+}
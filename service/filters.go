@@ -0,0 +1,121 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+type listFlags struct {
+	SortBy  *string
+	SortDir *string
+	Limit   *int
+	Offset  *int
+}
+
+func parseListFlags(ctx *cli.Context) (res listFlags, err error) {
+	if sortBy := ctx.String(models.FlagSortBy); len(sortBy) > 0 {
+		res.SortBy = &sortBy
+	}
+
+	if sortDir := ctx.String(models.FlagSortDir); len(sortDir) > 0 {
+		if sortDir != "asc" && sortDir != "desc" {
+			return res, errors.New("Wrong sort-dir flag value. Can be either \"asc\" or \"desc\".")
+		}
+		res.SortDir = &sortDir
+	}
+
+	if limit := ctx.Int(models.FlagLimit); limit != 0 {
+		if limit < 0 {
+			return res, errors.New("Limit flag value can't be negative")
+		}
+		res.Limit = &limit
+	}
+
+	if offset := ctx.Int(models.FlagOffset); offset != 0 {
+		if offset < 0 {
+			return res, errors.New("Offset flag value can't be negative")
+		}
+		res.Offset = &offset
+	}
+
+	return res, nil
+}
+
+func parseJobFilterFlags(ctx *cli.Context) (filter client.JobFilter, err error) {
+	l, err := parseListFlags(ctx)
+	if err != nil {
+		return filter, err
+	}
+
+	filter = client.JobFilter{
+		SortBy:  l.SortBy,
+		SortDir: l.SortDir,
+		Limit:   l.Limit,
+		Offset:  l.Offset,
+	}
+
+	if status := ctx.String(models.FlagStatus); len(status) > 0 {
+		jobStatus, ok := apiModels.StringToJobStatus(status)
+		if !ok {
+			return filter, errors.New("Wrong status flag value. Can be either \"pending\" or \"active\".")
+		}
+		filter.Status = &jobStatus
+	}
+
+	return filter, nil
+}
+
+func parseRunFilterFlags(ctx *cli.Context) (filter client.RunFilter, err error) {
+	l, err := parseListFlags(ctx)
+	if err != nil {
+		return filter, err
+	}
+
+	filter = client.RunFilter{
+		SortBy:  l.SortBy,
+		SortDir: l.SortDir,
+		Limit:   l.Limit,
+		Offset:  l.Offset,
+	}
+
+	if jobId := ctx.String(models.FlagJobId); len(jobId) > 0 {
+		filter.JobID = &jobId
+	}
+
+	if status := ctx.String(models.FlagStatus); len(status) > 0 {
+		runStatus, ok := apiModels.StringToJobRunStatus(status)
+		if !ok {
+			return filter, errors.New("Wrong status flag value. Can be either \"started\", \"success\" or \"error\".")
+		}
+		filter.Status = &runStatus
+	}
+
+	return filter, nil
+}
+
+func parseEventFilterFlags(ctx *cli.Context) (filter client.EventFilter, err error) {
+	l, err := parseListFlags(ctx)
+	if err != nil {
+		return filter, err
+	}
+
+	filter = client.EventFilter{
+		SortBy:  l.SortBy,
+		SortDir: l.SortDir,
+		Limit:   l.Limit,
+		Offset:  l.Offset,
+	}
+
+	if event := ctx.String(models.FlagEvent); len(event) > 0 {
+		name, ok := apiModels.StringToEventName(event)
+		if !ok {
+			return filter, errors.New("Wrong event flag value. Can be one of the event names, i.e. \"tag_discovery\" or \"run_success\".")
+		}
+		filter.Name = &name
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/models"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+func newFilterContext(t *testing.T, args []string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(models.FlagStatus, "", "")
+	set.String(models.FlagJobId, "", "")
+	set.String(models.FlagEvent, "", "")
+	set.String(models.FlagSortBy, "", "")
+	set.String(models.FlagSortDir, "", "")
+	set.Int(models.FlagLimit, 0, "")
+	set.Int(models.FlagOffset, 0, "")
+	err := set.Parse(args)
+	assert.Nil(t, err)
+
+	return cli.NewContext(nil, set, nil)
+}
+
+func Test_parseListFlags(t *testing.T) {
+	l, err := parseListFlags(newFilterContext(t, []string{}))
+	assert.Nil(t, err)
+	assert.Nil(t, l.SortBy)
+	assert.Nil(t, l.SortDir)
+	assert.Nil(t, l.Limit)
+	assert.Nil(t, l.Offset)
+
+	l, err = parseListFlags(newFilterContext(t, []string{"-sort", "created_at", "-sort-dir", "asc", "-limit", "5", "-offset", "10"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "created_at", *l.SortBy)
+	assert.Equal(t, "asc", *l.SortDir)
+	assert.Equal(t, 5, *l.Limit)
+	assert.Equal(t, 10, *l.Offset)
+
+	_, err = parseListFlags(newFilterContext(t, []string{"-sort-dir", "up"}))
+	assert.Error(t, err)
+
+	_, err = parseListFlags(newFilterContext(t, []string{"-limit", "-1"}))
+	assert.Error(t, err)
+
+	_, err = parseListFlags(newFilterContext(t, []string{"-offset", "-1"}))
+	assert.Error(t, err)
+}
+
+func Test_parseJobFilterFlags(t *testing.T) {
+	f, err := parseJobFilterFlags(newFilterContext(t, []string{"-status", "pending"}))
+	assert.Nil(t, err)
+	assert.Equal(t, apiModels.JobStatusPending, *f.Status)
+
+	_, err = parseJobFilterFlags(newFilterContext(t, []string{"-status", "finished"}))
+	assert.Error(t, err)
+}
+
+func Test_parseRunFilterFlags(t *testing.T) {
+	f, err := parseRunFilterFlags(newFilterContext(t, []string{"-status", "success", "-job-id", "id"}))
+	assert.Nil(t, err)
+	assert.Equal(t, apiModels.JobRunStatusSuccess, *f.Status)
+	assert.Equal(t, "id", *f.JobID)
+
+	_, err = parseRunFilterFlags(newFilterContext(t, []string{"-status", "active"}))
+	assert.Error(t, err)
+}
+
+func Test_parseEventFilterFlags(t *testing.T) {
+	f, err := parseEventFilterFlags(newFilterContext(t, []string{"-event", "job_finished"}))
+	assert.Nil(t, err)
+	assert.Equal(t, apiModels.EventNameJobFinished, *f.Name)
+
+	_, err = parseEventFilterFlags(newFilterContext(t, []string{"-event", "job_started"}))
+	assert.Error(t, err)
+}
@@ -1,10 +1,47 @@
 package service
 
 import (
+	"github.com/taglme/nfc-cli/config"
 	"github.com/taglme/nfc-cli/models"
 	"github.com/urfave/cli/v2"
 )
 
+func (s *appService) getGlobalFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        models.FlagFormat,
+			Value:       models.OutputFormatText,
+			Usage:       "Output format. Optional. Can be text, json or ndjson. In json and ndjson formats results are printed to stdout and progress messages to stderr",
+			Destination: &s.format,
+		},
+		&cli.StringFlag{
+			Name:        models.FlagProfile,
+			Usage:       "Profile of config file with default flag values. Optional. Without profile only defaults of config file are used",
+			EnvVars:     []string{configEnvName(models.FlagProfile)},
+			Destination: &s.profile,
+		},
+		&cli.StringFlag{
+			Name:        models.FlagConfig,
+			Value:       config.DefaultPath(),
+			Usage:       "Config file with default flag values. Optional",
+			EnvVars:     []string{configEnvName(models.FlagConfig)},
+			Destination: &s.configPath,
+		},
+	}
+}
+
+// getTagsFlags returns flags of tags command. Tag type flag isn't in the flags map as it has the same name as NDEF type flag
+func (s *appService) getTagsFlags() []cli.Flag {
+	return []cli.Flag{
+		s.flagsMap[models.FlagHost],
+		s.flagsMap[models.FlagAdapter],
+		&cli.StringFlag{
+			Name:  models.FlagTagType,
+			Usage: "Tag type filter. Optional. Can be nfc, barcode or bluetooth",
+		},
+	}
+}
+
 func (s *appService) getFlagsMap() map[string]cli.Flag {
 	return map[string]cli.Flag{
 		models.FlagHost: &cli.StringFlag{
@@ -13,12 +50,16 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 			Usage:       "Target host and port",
 			Destination: &s.host,
 		},
-		models.FlagAdapter: &cli.IntFlag{
+		models.FlagAdapter: &cli.StringFlag{
 			Name:        models.FlagAdapter,
-			Value:       1,
-			Usage:       "Adapter",
+			Value:       "1",
+			Usage:       "Adapter. Can be adapter ID, name substring or glob pattern, type as \"type:nfc\" or index in adapters list",
 			Destination: &s.adapter,
 		},
+		models.FlagAllAdapters: &cli.BoolFlag{
+			Name:  models.FlagAllAdapters,
+			Usage: "Run the job on all NFC adapters. Several adapters can also be set with comma separated adapter flag, i.e. \"1,2,3\". Optional.",
+		},
 		models.FlagRepeat: &cli.IntFlag{
 			Name:        models.FlagRepeat,
 			Value:       1,
@@ -42,6 +83,16 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 			Usage:       "Job timeout time in seconds. Optional. If absent equals 60",
 			Destination: &s.timeout,
 		},
+		models.FlagDeadline: &cli.DurationFlag{
+			Name:        models.FlagDeadline,
+			Usage:       "Time to wait for all runs, i.e. 5m. Optional. When exceeded, jobs are deleted and the command exits",
+			Destination: &s.deadline,
+		},
+		models.FlagIdle: &cli.DurationFlag{
+			Name:        models.FlagIdle,
+			Usage:       "Time to wait for the next tag, i.e. 30s. Optional. When no tag is presented in time, jobs are deleted and the command exits",
+			Destination: &s.idle,
+		},
 		models.FlagFile: &cli.StringFlag{
 			Name:        models.FlagFile,
 			Usage:       "File name for loading data to form a command. Optional. If absent, data is formed from the arguments of the command. If present, then the command arguments are ignored, data is taken from the file.",
@@ -53,6 +104,23 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 			Usage:       "An indication of the need for authorization before starting operations. The value of the argument is indicated as an array of bytes in hex format. Example \"03 AD F3 41\"",
 			Destination: &s.auth,
 		},
+		models.FlagAuthDerive: &cli.BoolFlag{
+			Name:  models.FlagAuthDerive,
+			Usage: "Authorize with password derived from the tag UID and master key. Tags are processed one by one. Optional.",
+		},
+		models.FlagDerive: &cli.BoolFlag{
+			Name:  models.FlagDerive,
+			Usage: "Use password derived from the tag UID and master key. Tags are processed one by one. Optional.",
+		},
+		models.FlagKdf: &cli.StringFlag{
+			Name:  models.FlagKdf,
+			Usage: "Algorithm of password derivation: \"hmac-sha256\" or \"aes-cmac\". Optional.",
+			Value: "hmac-sha256",
+		},
+		models.FlagKeyFile: &cli.StringFlag{
+			Name:  models.FlagKeyFile,
+			Usage: "File with HEX master key of derived passwords. NFC_CLI_MASTER_KEY environment variable is used when the flag isn't set. Optional.",
+		},
 		models.FlagJobName: &cli.StringFlag{
 			Name:        models.FlagJobName,
 			Usage:       "Task name of the created task. Optional. If absent, then name created in accordance with the command used.",
@@ -63,10 +131,42 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 			Value: false,
 			Usage: "Flag indicating the need to save it instead of sending a job to the server to the job file specified in the output parameter.",
 		},
+		models.FlagStatus: &cli.StringFlag{
+			Name:  models.FlagStatus,
+			Usage: "Status filter. Optional. For jobs can be pending or active. For runs can be started, success or error.",
+		},
+		models.FlagSortBy: &cli.StringFlag{
+			Name:  models.FlagSortBy,
+			Usage: "Sort field for list. Optional.",
+		},
+		models.FlagSortDir: &cli.StringFlag{
+			Name:  models.FlagSortDir,
+			Usage: "Sort direction for list. Optional. Can be asc or desc.",
+		},
+		models.FlagLimit: &cli.IntFlag{
+			Name:  models.FlagLimit,
+			Usage: "Limit number of items in list. Optional. If absent, server default is used for jobs and all pages are fetched for runs.",
+		},
+		models.FlagOffset: &cli.IntFlag{
+			Name:  models.FlagOffset,
+			Usage: "Offset from start of list. Optional.",
+		},
+		models.FlagJobId: &cli.StringFlag{
+			Name:  models.FlagJobId,
+			Usage: "Job ID filter. Optional.",
+		},
+		models.FlagEvent: &cli.StringFlag{
+			Name:  models.FlagEvent,
+			Usage: "Event name filter. Optional. Example \"tag_discovery\", \"adapter_release\", \"job_finished\", \"run_success\"",
+		},
+		models.FlagFollow: &cli.BoolFlag{
+			Name:  models.FlagFollow,
+			Value: false,
+			Usage: "Stream new events as they happen instead of listing the events log.",
+		},
 		models.FlagPwd: &cli.StringFlag{
-			Name:     models.FlagPwd,
-			Usage:    "Password to get an access to the memory of the NFC tag. The value of the argument is indicated as an array of bytes in hex format. Example \"03 AD F3 41\"",
-			Required: true,
+			Name:  models.FlagPwd,
+			Usage: "Password to get an access to the memory of the NFC tag. The value of the argument is indicated as an array of bytes in hex format. Example \"03 AD F3 41\". Mandatory unless derive flag is used",
 		},
 		models.FlagTarget: &cli.StringFlag{
 			Name:  models.FlagTarget,
@@ -80,15 +180,96 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 		},
 
 		models.FlagNdefType: &cli.StringFlag{
-			Name:     models.FlagNdefType,
-			Usage:    "Indication of the type of record. Mandatory",
+			Name:  models.FlagNdefType,
+			Usage: "Indication of the type of record. Mandatory unless record flags are used",
+		},
+		models.FlagRecord: &cli.StringSliceFlag{
+			Name:  models.FlagRecord,
+			Usage: "NDEF record in form \"type:field=value;field=value\", where fields are named as write command flags. Can be repeated to write NDEF message of several records. Example --record \"url:url=https://tagl.me\" --record \"aar:package-name=me.tagl\"",
+		},
+		models.FlagVar: &cli.StringSliceFlag{
+			Name:  models.FlagVar,
+			Usage: "Variable of the job file in form \"NAME=value\". Can be repeated. Variables which aren't set are taken from environment and vars section of the file",
+		},
+		models.FlagValidate: &cli.BoolFlag{
+			Name:  models.FlagValidate,
+			Usage: "Check the job file and report all problems with line numbers without adding jobs. Optional.",
+		},
+		models.FlagDryRun: &cli.BoolFlag{
+			Name:  models.FlagDryRun,
+			Usage: "Print jobs of the job file with resolved variables and includes without connecting to the server. Optional.",
+		},
+		models.FlagMessageFile: &cli.StringFlag{
+			Name:  models.FlagMessageFile,
+			Usage: "JSON or YAML file with list of NDEF records to write. Every record has ndef-type field and fields named as write command flags. Optional.",
+		},
+		models.FlagEncoding: &cli.StringFlag{
+			Name:  models.FlagEncoding,
+			Value: "hex",
+			Usage: "Encoding of NDEF message bytes. Optional. Can be hex or bin. In bin encoding raw bytes are written to stdout or read from stdin",
+		},
+		models.FlagTlv: &cli.BoolFlag{
+			Name:  models.FlagTlv,
+			Usage: "Wrap NDEF message into TLV block as it is stored in tag memory. Optional.",
+		},
+		models.FlagAnalyze: &cli.BoolFlag{
+			Name:  models.FlagAnalyze,
+			Usage: "Print annotated memory layout and NDEF message of the dumped tag. Optional.",
+		},
+		models.FlagDumpFormat: &cli.StringFlag{
+			Name:  models.FlagDumpFormat,
+			Usage: "Format of the dump file: json (job run), bin, hex, eml, proxmark or flipper. Optional. By default dumps are written as job runs, dump export detects format by output file extension: .bin, .hex, .eml, .nfc",
+		},
+		models.FlagDiff: &cli.StringFlag{
+			Name:  models.FlagDiff,
+			Usage: "Reference dump file to compare dumped tag with. Optional.",
+		},
+		models.FlagFrom: &cli.StringFlag{
+			Name:     models.FlagFrom,
+			Usage:    "Dump file to restore the tag from. The first dump of the file is used",
 			Required: true,
 		},
+		models.FlagAllow: &cli.StringSliceFlag{
+			Name:  models.FlagAllow,
+			Usage: "Memory regions to restore besides user memory: uid, lock or config. Optional. Can be repeated. Password pages are never restored",
+		},
 		models.FlagProtect: &cli.BoolFlag{
 			Name:  models.FlagProtect,
 			Usage: "The need to lock the label after recording. Optional.",
 		},
 
+		models.FlagVerify: &cli.BoolFlag{
+			Name:  models.FlagVerify,
+			Usage: "Read NDEF message back after recording and fail on mismatch. With protect flag tag is locked by the same job after reading back, the server can't skip locking, so tag which fails verification is locked too. Optional.",
+		},
+
+		models.FlagBatch: &cli.StringFlag{
+			Name:  models.FlagBatch,
+			Usage: "CSV file with one record per row written to one tag per row. Header names record fields the same way as command flags, NDEF type is set by ndef-type column or flag. Optional.",
+		},
+
+		models.FlagBatchResults: &cli.StringFlag{
+			Name:  models.FlagBatchResults,
+			Usage: "CSV file where written tag UID is recorded for every row of the batch. Default is <batch>.results.csv. Optional.",
+		},
+
+		models.FlagResume: &cli.BoolFlag{
+			Name:  models.FlagResume,
+			Usage: "Continue the batch skipping rows written according to the batch results file. Optional.",
+		},
+
+		models.FlagSigKey: &cli.StringFlag{
+			Name:    models.FlagSigKey,
+			Usage:   "HEX key of HMAC-SHA256 signature of the tag UID rendered for {sig} placeholder. Optional.",
+			EnvVars: []string{"NFC_CLI_SIG_KEY"},
+		},
+
+		models.FlagCounterStart: &cli.IntFlag{
+			Name:  models.FlagCounterStart,
+			Usage: "First value of {counter} placeholder. Optional.",
+			Value: 1,
+		},
+
 		models.FlagNdefTypeRawId: &cli.StringFlag{
 			Name:  models.FlagNdefTypeRawId,
 			Usage: "NDEF raw type id field",
@@ -100,7 +281,7 @@ func (s *appService) getFlagsMap() map[string]cli.Flag {
 		},
 		models.FlagNdefTypeType: &cli.StringFlag{
 			Name:  models.FlagNdefTypeType,
-			Usage: "NDEF raw type type field",
+			Usage: "NDEF raw/mime type type field",
 		},
 		models.FlagNdefTypeRawPayload: &cli.StringFlag{
 			Name:  models.FlagNdefTypeRawPayload,
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/jobfile"
+	"github.com/taglme/nfc-cli/models"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+)
+
+// cmdRunCheck validates job file of run command or prints its jobs with dry-run flag without connecting to the server
+func (s *appService) cmdRunCheck(ctx *cli.Context) error {
+	file := ctx.String(models.FlagFile)
+	if !ctx.Bool(models.FlagDryRun) {
+		return s.lintJobFile(ctx, file)
+	}
+
+	vars, err := parseVarFlags(ctx.StringSlice(models.FlagVar))
+	if err != nil {
+		return err
+	}
+	entries, err := jobfile.Load(file, vars)
+	if err != nil {
+		return withExitCode(models.ExitValidation, err)
+	}
+
+	jobs := make([]apiModels.NewJob, len(entries))
+	for i, e := range entries {
+		jobs[i] = e.Resolve(s.timeout, s.jobName)
+	}
+	if s.isJsonOutput() {
+		return s.printJson(jobs)
+	}
+
+	for i, nj := range jobs {
+		printNewJob(nj, entries[i].Line)
+	}
+
+	return nil
+}
+
+func (s *appService) cmdJobsLint(ctx *cli.Context) error {
+	file := ctx.Args().First()
+	if len(file) == 0 {
+		return errors.New("Job file argument is required")
+	}
+
+	return s.lintJobFile(ctx, file)
+}
+
+// lintJobFile reports all problems of the job file. Error is returned when the file isn't valid
+func (s *appService) lintJobFile(ctx *cli.Context, file string) error {
+	vars, err := parseVarFlags(ctx.StringSlice(models.FlagVar))
+	if err != nil {
+		return err
+	}
+
+	out := models.JobFileOutput{File: file, Problems: []models.JobFileProblemOutput{}}
+	entries, err := jobfile.Load(file, vars)
+	if fileErr, ok := err.(*jobfile.Error); ok {
+		for _, p := range fileErr.Problems {
+			out.Problems = append(out.Problems, models.JobFileProblemOutput{Line: p.Line, Message: p.Message})
+		}
+	} else if err != nil {
+		return withExitCode(models.ExitValidation, err)
+	}
+
+	out.Valid = err == nil
+	out.Jobs = len(entries)
+	for _, e := range entries {
+		out.Runs += e.Job.Repeat
+	}
+
+	if s.isJsonOutput() {
+		printErr := s.printJson(out)
+		if printErr != nil {
+			return printErr
+		}
+	} else if out.Valid {
+		fmt.Printf("Job file %s is valid: %d jobs, %d runs\n", file, out.Jobs, out.Runs)
+	}
+
+	if err != nil {
+		return withExitCode(models.ExitValidation, err)
+	}
+
+	return nil
+}
+
+func printNewJob(nj apiModels.NewJob, line int) {
+	fmt.Printf("Job %s (line %d):\n", color.CyanString(nj.JobName), line)
+	fmt.Printf("   Repeat: %d\n", nj.Repeat)
+	fmt.Printf("   Expire after: %d sec\n", nj.ExpireAfter)
+	fmt.Println("   Steps:")
+	for i, step := range nj.Steps {
+		fmt.Printf("   [Step %d] %s\n", i+1, step.Command)
+
+		js, err := step.ToJobStep()
+		if err == nil && js.Params != nil {
+			if params := js.Params.String(); len(params) > 0 {
+				fmt.Printf("   Params:\n%s\n", params)
+			}
+		}
+	}
+
+	fmt.Println()
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func runJobFileTestApp(t *testing.T, args ...string) (string, error) {
+	app := New(mock.NewRepositoryService(nil), func(string) {
+		t.Error("Server shouldn't be contacted")
+	}, opts.Config{})
+	var err error
+	out := captureStdout(t, func() {
+		err = app.run(append([]string{"nfc-cli"}, args...))
+	})
+
+	return out, err
+}
+
+func TestAppService_jobFileCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfc-cli")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "jobs.yaml")
+	err = ioutil.WriteFile(file, []byte(`jobs:
+  - name: Transmit
+    repeat: 2
+    steps:
+      - transmit_tag:
+          tx_bytes: ${TX}
+`), 0644)
+	assert.Nil(t, err)
+
+	out, err := runJobFileTestApp(t, "run", "--file", file, "--validate", "--var", "TX=30 00")
+	assert.Nil(t, err)
+	assert.Equal(t, "Job file "+file+" is valid: 1 jobs, 2 runs\n", out)
+
+	_, err = runJobFileTestApp(t, "jobs", "lint", file)
+	assert.EqualError(t, err, "Job file is not valid:\n"+file+":5: job 1 (Transmit): step 1: Variable TX is not set")
+	assert.Equal(t, models.ExitValidation, ExitCode(err))
+
+	out, err = runJobFileTestApp(t, "--format", "json", "jobs", "lint", "--var", "TX=3G", file)
+	assert.NotNil(t, err)
+	assert.JSONEq(t, `{"file": "`+file+`", "valid": false, "jobs": 0, "runs": 0, "problems": [
+		{"line": 5, "message": "job 1 (Transmit): step 1: Param tx_bytes should be HEX string, i.e. \"03 AD F3 41\""}
+	]}`, out)
+
+	out, err = runJobFileTestApp(t, "--format", "json", "run", "--file", file, "--dry-run", "--var", "TX=30 00", "--timeout", "30")
+	assert.Nil(t, err)
+	assert.JSONEq(t, `[{"job_name": "Transmit", "repeat": 2, "expire_after": 30, "steps": [
+		{"command": "transmit_tag", "params": {"tx_bytes": "MAA="}}
+	]}]`, out)
+
+	out, err = runJobFileTestApp(t, "run", "--file", file, "--dry-run", "--var", "TX=30 00")
+	assert.Nil(t, err)
+	assert.Contains(t, out, "Job Transmit (line 2):\n   Repeat: 2\n   Expire after: 60 sec\n   Steps:\n   [Step 1] transmit_tag\n")
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-cli/utils"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+)
+
+// readNdefMessageFile reads NDEF message from JSON or YAML file with list of records.
+// Every record has ndef-type field and fields named as write command flags.
+// Problems of all records are reported together with file line numbers.
+func readNdefMessageFile(filename string) ([]ndef.NdefPayload, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't read message file")
+	}
+
+	// scalars are decoded to strings as they are written in file, so hex values like 0341 are kept untouched
+	var items []map[string]string
+	err = yaml.Unmarshal(data, &items)
+	if err != nil {
+		return nil, errors.Wrap(err, "Can't parse message file")
+	}
+	if len(items) == 0 {
+		return nil, errors.New("Message file doesn't contain any records")
+	}
+
+	lines := utils.NewSourceLines(data)
+	records := make([]ndef.NdefPayload, len(items))
+	var problems []string
+	for i, item := range items {
+		records[i], err = parseMessageRecord(item)
+		if err == nil {
+			continue
+		}
+
+		field := ""
+		if fErr, ok := errors.Cause(err).(*ndefFieldError); ok {
+			field = fErr.field
+		}
+		problems = append(problems, describeRecord(lines, filename, i, field)+err.Error())
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New("Message file is not valid:\n" + strings.Join(problems, "\n"))
+	}
+
+	return records, nil
+}
+
+func parseMessageRecord(item map[string]string) (ndef.NdefPayload, error) {
+	ndefType, ok := item[models.FlagNdefType]
+	if !ok {
+		return nil, fieldError(models.FlagNdefType, errors.New("Field ndef-type is required"))
+	}
+
+	values := make(map[string]string, len(item))
+	for name, v := range item {
+		if name != models.FlagNdefType {
+			values[name] = v
+		}
+	}
+
+	fields, err := newNdefRecordFields(ndefType, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNdefPayload(ndefType, fields)
+}
+
+// describeRecord returns location of the record field in the message file source
+func describeRecord(lines utils.SourceLines, filename string, record int, field string) string {
+	path := []interface{}{record}
+	if len(field) > 0 {
+		path = append(path, field)
+	}
+
+	res := filename
+	if line := lines.Line(path...); line > 0 {
+		res += fmt.Sprintf(":%d", line)
+	}
+
+	res += fmt.Sprintf(": record %d", record+1)
+	if len(field) > 0 {
+		res += fmt.Sprintf(", field %s", field)
+	}
+
+	return res + ": "
+}
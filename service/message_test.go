@@ -0,0 +1,81 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/ndef"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeMessageFile(t *testing.T, content string) string {
+	f, err := ioutil.TempFile("", "message_test_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return f.Name()
+}
+
+func Test_readNdefMessageFile(t *testing.T) {
+	filename := writeMessageFile(t, `# badge
+- ndef-type: url
+  url: https://tagl.me
+- ndef-type: raw
+  tnf: 2
+  type: text/plain
+  payload: 0341
+- ndef-type: text
+  text: Hello
+`)
+	defer os.Remove(filename)
+
+	records, err := readNdefMessageFile(filename)
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, &ndef.NdefRecordPayloadUrl{Url: "https://tagl.me"}, records[0])
+	assert.Equal(t, []byte{0x03, 0x41}, records[1].(*ndef.NdefRecordPayloadRaw).Payload)
+	assert.Equal(t, "English", records[2].(*ndef.NdefRecordPayloadTypeText).Lang)
+}
+
+func Test_readNdefMessageFile_Errors(t *testing.T) {
+	filename := writeMessageFile(t, `- ndef-type: url
+  url: https://tagl.me
+- ndef-type: vcard
+  first-name: John
+  email: john
+- url: https://tagl.me
+- ndef-type: aar
+  package: me.tagl
+`)
+	defer os.Remove(filename)
+
+	_, err := readNdefMessageFile(filename)
+	assert.EqualError(t, err, "Message file is not valid:\n"+
+		filename+":5: record 2, field email: Flag email should contain valid email.\n"+
+		filename+":6: record 3, field ndef-type: Field ndef-type is required\n"+
+		filename+":8: record 4, field package: Unknown field \"package\" for aar record. Available fields: [package-name]")
+
+	json := writeMessageFile(t, `[
+  {"ndef-type": "url", "url": "https://tagl.me"},
+  {
+    "ndef-type": "geo",
+    "latitude": "55.75",
+    "longitude": "200"
+  }
+]`)
+	defer os.Remove(json)
+
+	_, err = readNdefMessageFile(json)
+	assert.EqualError(t, err, "Message file is not valid:\n"+
+		json+":6: record 2, field longitude: Wrong latitude value – can be from -180 to 180")
+
+	_, err = readNdefMessageFile("not_existing_file.yaml")
+	assert.Error(t, err)
+}
@@ -6,11 +6,171 @@ import (
 	"github.com/taglme/nfc-cli/models"
 	"github.com/taglme/nfc-cli/ndef"
 	"github.com/urfave/cli/v2"
+	"sort"
+	"strconv"
+	"strings"
 )
 
-func (s *appService) parseNdefPayloadFlags(ctx *cli.Context) (res ndef.NdefPayload, err error) {
-	ndefType := ctx.String(models.FlagNdefType)
+// ndefFields gives access to NDEF record fields by their flag names. Implemented by cli.Context and ndefRecordFields
+type ndefFields interface {
+	String(name string) string
+	Int(name string) int
+}
+
+// ndefRecordFields holds fields of the record given with record flag
+type ndefRecordFields map[string]string
+
+func (f ndefRecordFields) String(name string) string {
+	return f[name]
+}
+
+// Int returns -1 for missing or not numeric field, same as tnf flag default
+func (f ndefRecordFields) Int(name string) int {
+	i, err := strconv.Atoi(f[name])
+	if err != nil {
+		return -1
+	}
+
+	return i
+}
+
+// parseNdefMessageFlags returns records given with one of message-file, repeated record flags or ndef-type and its field flags
+func (s *appService) parseNdefMessageFlags(ctx *cli.Context) ([]ndef.NdefPayload, error) {
+	specs := ctx.StringSlice(models.FlagRecord)
+	file := ctx.String(models.FlagMessageFile)
+
+	sources := 0
+	for _, set := range []bool{ctx.IsSet(models.FlagNdefType), len(specs) > 0, len(file) > 0} {
+		if set {
+			sources++
+		}
+	}
+
+	switch {
+	case sources == 0:
+		return nil, errors.New("One of ndef-type, record or message-file flags should be set")
+	case sources > 1:
+		return nil, errors.New("Flags ndef-type, record and message-file can't be used together")
+	case len(file) > 0:
+		return readNdefMessageFile(file)
+	case len(specs) == 0:
+		payload, err := s.parseNdefPayloadFlags(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ndef.NdefPayload{payload}, nil
+	}
+
+	records := make([]ndef.NdefPayload, len(specs))
+	for i, spec := range specs {
+		ndefType, fields, err := parseNdefRecordSpec(spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Record %d", i+1)
+		}
+
+		records[i], err = parseNdefPayload(ndefType, fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Record %d", i+1)
+		}
+	}
+
+	return records, nil
+}
+
+// parseNdefRecordSpec parses record flag value in form "type:field=value;field=value".
+// Field names are the same as write command flags. Semicolon in value can be escaped as "\;"
+func parseNdefRecordSpec(spec string) (models.NdefType, ndefRecordFields, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	ndefType := strings.TrimSpace(parts[0])
+
+	values := map[string]string{}
+	if len(parts) > 1 {
+		for _, f := range splitEscaped(parts[1], ';') {
+			if len(strings.TrimSpace(f)) == 0 {
+				continue
+			}
+
+			kv := strings.SplitN(f, "=", 2)
+			if len(kv) < 2 {
+				return ndefType, nil, errors.New(fmt.Sprintf("Field \"%s\" should be in form field=value", f))
+			}
+			values[strings.TrimSpace(kv[0])] = kv[1]
+		}
+	}
+
+	fields, err := newNdefRecordFields(ndefType, values)
+
+	return ndefType, fields, err
+}
+
+// newNdefRecordFields checks that all values are known fields of the NDEF type and sets defaults of missing fields
+func newNdefRecordFields(ndefType models.NdefType, values map[string]string) (ndefRecordFields, error) {
+	known, ok := models.NdefTypeFields[ndefType]
+	if !ok {
+		return nil, fieldError(models.FlagNdefType, errors.New(fmt.Sprintf("There's no Ndef Record Payload struct for such Ndef Type. Choose one from available: %v", models.NdefTypeValues)))
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := ndefRecordFields{models.FlagNdefTypeLang: "English"}
+	for _, name := range names {
+		if !containsFlag(known, name) {
+			return nil, fieldError(name, errors.New(fmt.Sprintf("Unknown field \"%s\" for %s record. Available fields: %v", name, ndefType, known)))
+		}
+		fields[name] = values[name]
+	}
+
+	return fields, nil
+}
+
+func splitEscaped(s string, sep rune) []string {
+	var res []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != sep {
+				cur.WriteRune('\\')
+			}
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == sep:
+			res = append(res, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if escaped {
+		cur.WriteRune('\\')
+	}
+
+	return append(res, cur.String())
+}
 
+func containsFlag(flags []models.Flag, name string) bool {
+	for _, f := range flags {
+		if f == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *appService) parseNdefPayloadFlags(ctx *cli.Context) (ndef.NdefPayload, error) {
+	return parseNdefPayload(ctx.String(models.FlagNdefType), ctx)
+}
+
+func parseNdefPayload(ndefType models.NdefType, ctx ndefFields) (res ndef.NdefPayload, err error) {
 	switch ndefType {
 	case models.NdefTypeRaw:
 		tnf := ctx.Int(models.FlagNdefTypeRawTnf)
@@ -74,3 +234,105 @@ func (s *appService) parseNdefPayloadFlags(ctx *cli.Context) (res ndef.NdefPaylo
 
 	return nil, errors.New(fmt.Sprintf("There's no Ndef Record Payload struct for such Ndef Type. Choose one from available: %v", models.NdefTypeValues))
 }
+
+// ndefPayloadToFields is the reverse of parseNdefPayload. Fields are named as write command flags,
+// so they can be used in record flags and message files
+func ndefPayloadToFields(p ndef.NdefPayload) (models.NdefType, ndefRecordFields) {
+	switch p := p.(type) {
+	case *ndef.NdefRecordPayloadRaw:
+		return models.NdefTypeRaw, ndefRecordFields{
+			models.FlagNdefTypeRawTnf:     strconv.Itoa(p.Tnf),
+			models.FlagNdefTypeType:       p.Type,
+			models.FlagNdefTypeRawId:      p.ID,
+			models.FlagNdefTypeRawPayload: fmt.Sprintf("% X", p.Payload),
+		}
+	case *ndef.NdefRecordPayloadUrl:
+		return models.NdefTypeUrl, ndefRecordFields{models.FlagNdefTypeUrl: p.Url}
+	case *ndef.NdefRecordPayloadTypeText:
+		return models.NdefTypeText, ndefRecordFields{models.FlagNdefTypeText: p.Text, models.FlagNdefTypeLang: p.Lang}
+	case *ndef.NdefRecordPayloadUri:
+		return models.NdefTypeUri, ndefRecordFields{models.FlagNdefUri: p.Uri}
+	case *ndef.NdefRecordPayloadVcard:
+		return models.NdefTypeVcard, ndefRecordFields{
+			models.FlagNdefTypeVcardAddressCity:       p.AddressCity,
+			models.FlagNdefTypeVcardAddressCountry:    p.AddressCountry,
+			models.FlagNdefTypeVcardAddressPostalCode: p.AddressPostalCode,
+			models.FlagNdefTypeVcardAddressRegion:     p.AddressRegion,
+			models.FlagNdefTypeVcardAddressStreet:     p.AddressStreet,
+			models.FlagNdefTypeVcardEmail:             p.Email,
+			models.FlagNdefTypeVcardFirstName:         p.FirstName,
+			models.FlagNdefTypeVcardLastName:          p.LastName,
+			models.FlagNdefTypeVcardOrganization:      p.Organization,
+			models.FlagNdefTypeVcardPhoneCell:         p.PhoneCell,
+			models.FlagNdefTypeVcardPhoneHome:         p.PhoneHome,
+			models.FlagNdefTypeVcardPhoneWork:         p.PhoneWork,
+			models.FlagNdefTypeTitle:                  p.Title,
+			models.FlagNdefTypeVcardSite:              p.Site,
+		}
+	case *ndef.NdefRecordPayloadMime:
+		content := p.ContentASCII
+		if p.Format == models.MimeFormatHex {
+			content = fmt.Sprintf("% X", p.ContentHEX)
+		}
+		return models.NdefTypeMime, ndefRecordFields{
+			models.FlagNdefTypeType:        p.Type,
+			models.FlagNdefTypeMimeFormat:  string(p.Format),
+			models.FlagNdefTypeMimeContent: content,
+		}
+	case *ndef.NdefRecordPayloadPhone:
+		return models.NdefTypePhone, ndefRecordFields{models.FlagNdefTypePhone: p.PhoneNumber}
+	case *ndef.NdefRecordPayloadGeo:
+		return models.NdefTypeGeo, ndefRecordFields{models.FlagNdefTypeGeoLat: p.Latitude, models.FlagNdefTypeGeoLon: p.Longitude}
+	case *ndef.NdefRecordPayloadAar:
+		return models.NdefTypeAar, ndefRecordFields{models.FlagNdefTypeAarPackage: p.PackageName}
+	case *ndef.NdefRecordPayloadPoster:
+		return models.NdefTypePoster, ndefRecordFields{models.FlagNdefTypeTitle: p.Title, models.FlagNdefUri: p.Uri}
+	}
+
+	return "", ndefRecordFields{}
+}
+
+// ndefPayloadsToMaps converts records to maps with ndef-type and non-empty record fields for machine-readable output
+func ndefPayloadsToMaps(payloads []ndef.NdefPayload) []map[string]string {
+	res := make([]map[string]string, len(payloads))
+	for i, p := range payloads {
+		ndefType, fields := ndefPayloadToFields(p)
+		res[i] = map[string]string{models.FlagNdefType: ndefType}
+		for _, name := range models.NdefTypeFields[ndefType] {
+			if len(fields[name]) > 0 {
+				res[i][name] = fields[name]
+			}
+		}
+	}
+
+	return res
+}
+
+func printNdefRecords(payloads []ndef.NdefPayload, records []ndef.Record) {
+	if len(payloads) == 0 {
+		fmt.Println("NDEF message is empty")
+	}
+	for i, p := range payloads {
+		ndefType, fields := ndefPayloadToFields(p)
+		fmt.Printf("Record %d: %s (%s)\n", i+1, ndefType, describeNdefRecord(records[i]))
+		for _, name := range models.NdefTypeFields[ndefType] {
+			if len(fields[name]) > 0 {
+				fmt.Printf("   %s: %s\n", name, fields[name])
+			}
+		}
+	}
+}
+
+func describeNdefRecord(r ndef.Record) string {
+	var flags []string
+	for _, f := range []struct {
+		flag byte
+		name string
+	}{{ndef.FlagMB, "MB"}, {ndef.FlagME, "ME"}, {ndef.FlagCF, "CF"}, {ndef.FlagSR, "SR"}, {ndef.FlagIL, "IL"}} {
+		if r.Header&f.flag != 0 {
+			flags = append(flags, f.name)
+		}
+	}
+
+	return fmt.Sprintf("flags %s, TNF %d, type \"%s\", payload %d bytes", strings.Join(flags, " "), r.Tnf, r.Type, len(r.Payload))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"testing"
+)
+
+func Test_parseNdefRecordSpec(t *testing.T) {
+	ndefType, fields, err := parseNdefRecordSpec(`text:text=Hello\; world;lang=German`)
+	assert.Nil(t, err)
+	assert.Equal(t, models.NdefTypeText, ndefType)
+	assert.Equal(t, "Hello; world", fields.String(models.FlagNdefTypeText))
+	assert.Equal(t, "German", fields.String(models.FlagNdefTypeLang))
+
+	_, fields, err = parseNdefRecordSpec("raw:tnf=2;type=text/plain;payload=03 AD")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fields.Int(models.FlagNdefTypeRawTnf))
+	assert.Equal(t, "English", fields.String(models.FlagNdefTypeLang))
+
+	_, fields, err = parseNdefRecordSpec("raw:payload=03 AD")
+	assert.Nil(t, err)
+	assert.Equal(t, -1, fields.Int(models.FlagNdefTypeRawTnf))
+
+	_, _, err = parseNdefRecordSpec("unknown:url=http://tagl.me")
+	assert.Error(t, err)
+
+	_, _, err = parseNdefRecordSpec("url:text=Hello")
+	assert.EqualError(t, err, "Unknown field \"text\" for url record. Available fields: [url]")
+
+	_, _, err = parseNdefRecordSpec("url:https://tagl.me")
+	assert.EqualError(t, err, "Field \"https://tagl.me\" should be in form field=value")
+}
+
+func Test_parseNdefPayload(t *testing.T) {
+	_, fields, err := parseNdefRecordSpec("url:url=https://tagl.me")
+	assert.Nil(t, err)
+	p, err := parseNdefPayload(models.NdefTypeUrl, fields)
+	assert.Nil(t, err)
+	assert.Equal(t, &ndef.NdefRecordPayloadUrl{Url: "https://tagl.me"}, p)
+
+	_, fields, err = parseNdefRecordSpec("url:url=tagl.me")
+	assert.Nil(t, err)
+	_, err = parseNdefPayload(models.NdefTypeUrl, fields)
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"log"
+	"os"
+)
+
+// setupOutput runs before any command. In json and ndjson formats results are written to the original stdout,
+// while all human readable messages printed with fmt and color are redirected to stderr.
+func (s *appService) setupOutput(*cli.Context) error {
+	switch s.format {
+	case models.OutputFormatText:
+		return nil
+	case models.OutputFormatJson, models.OutputFormatNdjson:
+	default:
+		return errors.New("Wrong format flag value. Can be either \"text\", \"json\" or \"ndjson\".")
+	}
+
+	s.stdout, s.colorOutput = os.Stdout, color.Output
+	os.Stdout, color.Output = os.Stderr, color.Error
+
+	return nil
+}
+
+func (s *appService) restoreOutput(*cli.Context) error {
+	if s.stdout != nil {
+		os.Stdout, color.Output = s.stdout, s.colorOutput
+		s.stdout, s.colorOutput = nil, nil
+	}
+
+	return nil
+}
+
+func (s *appService) isJsonOutput() bool {
+	return s.format == models.OutputFormatJson || s.format == models.OutputFormatNdjson
+}
+
+// printJson prints single document. Documents are indented in json format and compact in ndjson format
+func (s *appService) printJson(doc interface{}) error {
+	out := s.stdout
+	if out == nil {
+		out = os.Stdout
+	}
+
+	encoder := json.NewEncoder(out)
+	if s.format == models.OutputFormatJson {
+		encoder.SetIndent("", "  ")
+	}
+
+	err := encoder.Encode(doc)
+	if err != nil {
+		return errors.Wrap(err, "Can't encode the output: ")
+	}
+
+	return nil
+}
+
+// printJsonList prints list as one document in json format or every item on separate line in ndjson format.
+// Lists with page info are wrapped into ListOutput.
+func (s *appService) printJsonList(items []interface{}, pageInfo *apiModels.PageInfo) error {
+	if s.format == models.OutputFormatNdjson {
+		for _, item := range items {
+			err := s.printJson(item)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if pageInfo == nil {
+		return s.printJson(items)
+	}
+
+	return s.printJson(models.ListOutput{
+		Total:  pageInfo.Total,
+		Length: pageInfo.Length,
+		Limit:  pageInfo.Limit,
+		Offset: pageInfo.Offset,
+		Items:  items,
+	})
+}
+
+// printJsonItem prints item of a stream (job runs, followed events) immediately in ndjson format.
+// In json format items are collected and printed as array by flushJsonItems on exit.
+func (s *appService) printJsonItem(item interface{}) {
+	if s.format == models.OutputFormatNdjson {
+		err := s.printJson(item)
+		if err != nil {
+			log.Println("Can't print the output: ", err)
+		}
+		return
+	}
+
+	s.jsonMutex.Lock()
+	s.jsonItems = append(s.jsonItems, item)
+	s.jsonMutex.Unlock()
+}
+
+func (s *appService) flushJsonItems() error {
+	if s.format != models.OutputFormatJson {
+		return nil
+	}
+
+	s.jsonMutex.Lock()
+	defer s.jsonMutex.Unlock()
+
+	items := s.jsonItems
+	if items == nil {
+		items = []interface{}{}
+	}
+	s.jsonItems = nil
+
+	return s.printJson(items)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func startWithStdout(t *testing.T, app *appService, args ...string) (string, error) {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	os.Args = append([]string{"nfc-cli"}, args...)
+	err = app.Start()
+
+	w.Close()
+	os.Stdout = stdout
+	out, _ := ioutil.ReadAll(r)
+
+	return string(out), err
+}
+
+func Test_outputFormat(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	cbCliStarted := func(string) {}
+	app := New(rep, cbCliStarted, opts.Config{Commit: "asd23d"})
+
+	_, err := startWithStdout(t, app, "--"+models.FlagFormat, "xml", models.CommandAdapters)
+	assert.EqualError(t, err, "Wrong format flag value. Can be either \"text\", \"json\" or \"ndjson\".")
+
+	out, err := startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandAdapters)
+	assert.Nil(t, err)
+	var adapters []apiModels.AdapterResource
+	assert.Nil(t, json.Unmarshal([]byte(out), &adapters))
+	assert.Equal(t, "mocked adapter id", adapters[0].AdapterID)
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandVersion)
+	assert.Nil(t, err)
+	var version models.VersionOutput
+	assert.Nil(t, json.Unmarshal([]byte(out), &version))
+	assert.Equal(t, "asd23d", version.Cli.Commit)
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandJobs, models.CommandList)
+	assert.Nil(t, err)
+	var list struct {
+		Total int                     `json:"total"`
+		Items []apiModels.JobResource `json:"items"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(out), &list))
+	assert.Equal(t, 1, list.Total)
+	assert.Equal(t, "mocked job id", list.Items[0].JobID)
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatNdjson, models.CommandTags)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Len(t, lines, 1)
+	var tag apiModels.TagResource
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &tag))
+	assert.Equal(t, "mocked tag id", tag.TagID)
+
+	out, err = startWithStdout(t, app, "--"+models.FlagFormat, models.OutputFormatJson, models.CommandEvents, "--"+models.FlagFollow)
+	assert.Nil(t, err)
+	var events []apiModels.EventResource
+	assert.Nil(t, json.Unmarshal([]byte(out), &events))
+	assert.Equal(t, "mocked event id", events[0].EventID)
+
+	out, err = startWithStdout(t, app, models.CommandAdapters)
+	assert.Nil(t, err)
+	assert.NotContains(t, out, "{")
+}
@@ -3,19 +3,31 @@ package service
 import (
 	"github.com/taglme/nfc-cli/models"
 	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-goclient/pkg/client"
 	apiModels "github.com/taglme/nfc-goclient/pkg/models"
 )
 
 type ApiService interface {
-	GetVersion() (apiModels.AppInfo, error)
-	GetAdapters(withOutput bool) ([]apiModels.Adapter, error)
-	GetJob(adapterId, id string) (apiModels.Job, error)
+	GetVersion(withOutput bool) (apiModels.AppInfo, error)
+	GetAdapters(adapterType *apiModels.AdapterType, withOutput bool) ([]apiModels.Adapter, error)
+	GetTags(adapterId string, tagType *apiModels.TagType, withOutput bool) ([]apiModels.Tag, error)
+	GetTag(adapterId, tagId string, withOutput bool) (apiModels.Tag, error)
+	GetJobs(adapterId string, filter client.JobFilter, withOutput bool) ([]apiModels.Job, apiModels.PageInfo, error)
+	GetJob(adapterId, id string, withOutput bool) (apiModels.Job, error)
+	DeleteJob(adapterId, id string) error
+	UpdateJobStatus(adapterId, id string, status apiModels.JobStatus, withOutput bool) (apiModels.Job, error)
+	GetRuns(adapterId string, filter client.RunFilter, withOutput bool) ([]apiModels.JobRun, apiModels.PageInfo, error)
+	GetRun(adapterId, id string, withOutput bool) (apiModels.JobRun, error)
+	GetEvents(adapterId *string, filter client.EventFilter, withOutput bool) ([]apiModels.Event, apiModels.PageInfo, error)
 	DeleteAdapterJobs(adapterId string) error
 	AddGenericJob(p models.GenericJobParams) (*apiModels.Job, *apiModels.NewJob, error)
-	AddSetPwdJob(p models.GenericJobParams, password []byte) (*apiModels.Job, *apiModels.NewJob, error)
+	AddSetPwdJob(p models.GenericJobParams, password []byte, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error)
 	AddTransmitJob(p models.GenericJobParams, txBytes []byte, target string) (*apiModels.Job, *apiModels.NewJob, error)
-	AddWriteJob(p models.GenericJobParams, r ndef.NdefPayload, protect bool) (*apiModels.Job, *apiModels.NewJob, error)
+	AddWriteJob(p models.GenericJobParams, records []ndef.NdefPayload, protect, verify bool) (*apiModels.Job, *apiModels.NewJob, error)
+	AddRestoreJob(p models.GenericJobParams, txCommands [][]byte) (*apiModels.Job, *apiModels.NewJob, error)
 	AddJobFromFile(adapterId string, filename string, p models.GenericJobParams) (int, error)
-	RunWsConnection(handler func(models.Event, interface{}), errHandler func(error)) error
+	RunWsConnection(handler func(models.Event, interface{}), errHandler func(error), reconnectHandler func()) error
+	FollowEvents(adapterId *string, name *apiModels.EventName, withOutput bool, eHandler func(apiModels.Event), errHandler func(error)) error
 	StopWsConnection() error
+	SetAdapterPrefix(enabled bool)
 }
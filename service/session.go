@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"log"
+	"sort"
+	"strings"
+)
+
+// multiAdapterCommands can run the same job on several adapters
+var multiAdapterCommands = map[string]bool{
+	models.CommandRead:  true,
+	models.CommandWrite: true,
+	models.CommandRun:   true,
+}
+
+// adapterSession tracks jobs added by the command to one adapter
+type adapterSession struct {
+	adapter apiModels.Adapter
+	// published is a number of runs of added jobs, left is a number of runs which aren't finished yet
+	published int
+	left      int
+	succeeded int
+	failed    int
+	// expired is a number of runs of jobs which expired before all runs succeeded
+	expired int
+	// jobs are submitted jobs with finished flag, repeats are their repeat numbers, runs are IDs of processed runs
+	jobs    map[string]bool
+	repeats map[string]int
+	runs    map[string]bool
+	// summaries are runs of every job in the order of their events
+	summaries []*jobSummary
+}
+
+// eventResource is a part of job or job run resource of the event identifying its adapter, job and run
+type eventResource struct {
+	AdapterID   string       `json:"adapter_id"`
+	JobID       string       `json:"job_id"`
+	JobName     string       `json:"job_name"`
+	RunID       string       `json:"run_id"`
+	Repeat      int          `json:"repeat"`
+	SuccessRuns int          `json:"success_runs"`
+	Results     []stepResult `json:"results"`
+}
+
+type stepResult struct {
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+func parseEventResource(data interface{}) eventResource {
+	var r eventResource
+	encoded, err := json.Marshal(data)
+	if err == nil {
+		_ = json.Unmarshal(encoded, &r)
+	}
+
+	return r
+}
+
+func (a *adapterSession) setJob(id string, finished bool) {
+	if a.jobs == nil {
+		a.jobs = map[string]bool{}
+	}
+	a.jobs[id] = finished
+}
+
+func (a *adapterSession) setRepeat(id string, repeat int) {
+	if a.repeats == nil {
+		a.repeats = map[string]int{}
+	}
+	a.repeats[id] = repeat
+}
+
+// jobRuns returns repeat of the submitted job and a number of its succeeded runs
+func (a *adapterSession) jobRuns(id string) (int, int) {
+	succeeded := 0
+	for _, j := range a.summaries {
+		if j.id == id {
+			succeeded = j.succeeded
+		}
+	}
+
+	return a.repeats[id], succeeded
+}
+
+// addRun returns false when the run is already processed
+func (a *adapterSession) addRun(id string) bool {
+	if a.runs == nil {
+		a.runs = map[string]bool{}
+	}
+	if a.runs[id] {
+		return false
+	}
+	a.runs[id] = true
+
+	return true
+}
+
+// expireJob counts runs of the job finished before all runs succeeded as expired. When other jobs of the session
+// are finished, no runs of the session are left as jobs for next tags are added after successful runs
+func (a *adapterSession) expireJob(runs int) {
+	for _, finished := range a.jobs {
+		if !finished {
+			if runs > a.left {
+				runs = a.left
+			}
+			a.expired += runs
+			a.left -= runs
+			return
+		}
+	}
+
+	a.expired += a.left
+	a.left = 0
+}
+
+func (s *appService) adapterId() string {
+	return s.session.adapter.AdapterID
+}
+
+func (s *appService) multiAdapter() bool {
+	return len(s.sessions) > 1
+}
+
+// getCommandAdapters resolves comma separated list of adapter flag or all NFC adapters with all-adapters flag
+func (s *appService) getCommandAdapters(ctx *cli.Context) ([]apiModels.Adapter, error) {
+	multi := ctx.Command != nil && multiAdapterCommands[ctx.Command.Name]
+	if multi && ctx.Bool(models.FlagAllAdapters) {
+		nfc := apiModels.AdapterTypeNfc
+		adapters, err := s.repository.GetAdapters(&nfc, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(adapters) == 0 {
+			return nil, errors.New("Adapters not found")
+		}
+		return adapters, nil
+	}
+
+	selectors := strings.Split(s.adapter, ",")
+	if len(selectors) > 1 && !multi {
+		return nil, validationError(errors.New("Several adapters can be used with read, write and run commands only"))
+	}
+
+	var adapters []apiModels.Adapter
+	selected := map[string]bool{}
+	for _, selector := range selectors {
+		a, err := s.getAdapter(selector)
+		if err != nil {
+			return nil, err
+		}
+		if selected[a.AdapterID] {
+			continue
+		}
+		selected[a.AdapterID] = true
+		adapters = append(adapters, a)
+	}
+
+	return adapters, nil
+}
+
+// eventSession returns session of the adapter of the event. Events of other adapters are ignored when
+// the command runs on several adapters
+func (s *appService) eventSession(r eventResource) *adapterSession {
+	if !s.multiAdapter() {
+		return s.session
+	}
+
+	for _, session := range s.sessions {
+		if session.adapter.AdapterID == r.AdapterID {
+			return session
+		}
+	}
+
+	return nil
+}
+
+// runsLeft returns a number of runs which aren't finished on all adapters
+func (s *appService) runsLeft() int {
+	left := 0
+	for _, session := range s.allSessions() {
+		left += session.left
+	}
+
+	return left
+}
+
+// runsProgress returns a number of published runs and a number of finished runs on all adapters
+func (s *appService) runsProgress() (int, int) {
+	published, finished := 0, 0
+	for _, session := range s.allSessions() {
+		published += session.published
+		finished += session.published - session.left
+	}
+
+	return published, finished
+}
+
+// resyncJobs processes runs and jobs finished while the server connection was lost, so counters of runs left
+// and output files are the same as if events were received
+func (s *appService) resyncJobs() {
+	type sessionJob struct {
+		session *adapterSession
+		id      string
+	}
+
+	s.sessionMutex.Lock()
+	var jobs []sessionJob
+	for _, session := range s.allSessions() {
+		for id, finished := range session.jobs {
+			if !finished {
+				jobs = append(jobs, sessionJob{session: session, id: id})
+			}
+		}
+	}
+	s.sessionMutex.Unlock()
+
+	synced := 0
+	for _, j := range jobs {
+		adapterId := j.session.adapter.AdapterID
+		jobId := j.id
+		runs, _, err := s.repository.GetRuns(adapterId, client.RunFilter{JobID: &jobId}, false)
+		if err != nil {
+			log.Printf("Can't get runs of job %s: %s", jobId, err)
+			continue
+		}
+		sort.Slice(runs, func(a, b int) bool {
+			return runs[a].CreatedAt.Before(runs[b].CreatedAt)
+		})
+
+		for _, r := range runs {
+			event := models.EventRunSuccess
+			if r.Status == apiModels.JobRunStatusError {
+				event = models.EventRunError
+			} else if r.Status != apiModels.JobRunStatusSuccess {
+				continue
+			}
+
+			s.sessionMutex.Lock()
+			processed := j.session.runs[r.RunID]
+			s.sessionMutex.Unlock()
+			if !processed {
+				synced++
+				s.eventHandler(event, r.ToResource())
+			}
+		}
+
+		// finished jobs have all runs succeeded or are removed from the server, runs of removed jobs
+		// which haven't succeeded are expired
+		finished := apiModels.JobResource{JobID: jobId, AdapterID: adapterId}
+		job, err := s.repository.GetJob(adapterId, jobId, false)
+		if err == nil && job.SuccessRuns < job.Repeat {
+			continue
+		}
+		if err == nil {
+			finished.Repeat, finished.SuccessRuns = job.Repeat, job.SuccessRuns
+		} else {
+			log.Printf("Can't get job %s after reconnection, it is considered finished: %s", jobId, err)
+			s.sessionMutex.Lock()
+			finished.Repeat, finished.SuccessRuns = j.session.jobRuns(jobId)
+			s.sessionMutex.Unlock()
+		}
+		s.eventHandler(models.EventJobFinished, finished)
+	}
+
+	if synced > 0 {
+		fmt.Printf("Synchronized %d runs finished while server connection was lost\n", synced)
+	}
+}
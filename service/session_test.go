@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"flag"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/urfave/cli/v2"
+	"testing"
+	"time"
+)
+
+func newSessionContext(t *testing.T, command string, args []string) *cli.Context {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.Bool(models.FlagAllAdapters, false, "")
+	err := set.Parse(args)
+	assert.Nil(t, err)
+
+	ctx := cli.NewContext(nil, set, nil)
+	ctx.Command = &cli.Command{Name: command}
+
+	return ctx
+}
+
+func TestAppService_getCommandAdapters(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+
+	app.adapter = "1,mocked adapter id"
+	adapters, err := app.getCommandAdapters(newSessionContext(t, models.CommandRead, []string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(adapters))
+
+	_, err = app.getCommandAdapters(newSessionContext(t, models.CommandDump, []string{}))
+	assert.EqualError(t, err, "Several adapters can be used with read, write and run commands only")
+
+	app.adapter = "1"
+	adapters, err = app.getCommandAdapters(newSessionContext(t, models.CommandWrite, []string{"-all-adapters"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "mocked adapter id", adapters[0].AdapterID)
+}
+
+func Test_eventHandler_sessions(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	first := &adapterSession{adapter: apiModels.Adapter{AdapterID: "first", Name: "Reader 1"}, published: 1, left: 1}
+	second := &adapterSession{adapter: apiModels.Adapter{AdapterID: "second", Name: "Reader 2"}, published: 2, left: 2}
+	app.sessions = []*adapterSession{first, second}
+	app.session = first
+	var handled []*adapterSession
+	app.runSuccessHandler = func(session *adapterSession, data interface{}) {
+		handled = append(handled, session)
+	}
+
+	app.eventHandler(models.EventRunSuccess, map[string]interface{}{"adapter_id": "second", "job_name": "Write"})
+	// runs are handled with the session of the event adapter, the session of the command isn't changed
+	assert.Equal(t, []*adapterSession{second}, handled)
+	assert.Equal(t, first, app.session)
+	app.eventHandler(models.EventRunError, map[string]interface{}{"adapter_id": "second", "job_name": "Write", "results": []map[string]interface{}{
+		{"command": "get_tags", "status": "success"},
+		{"command": "write_ndef", "status": "error", "message": "Tag is read only"},
+	}})
+	app.eventHandler(models.EventRunSuccess, map[string]interface{}{"adapter_id": "other"})
+	assert.Equal(t, 1, first.left)
+	assert.Equal(t, 1, second.left)
+	assert.Equal(t, 1, second.failed)
+	published, finished := app.runsProgress()
+	assert.Equal(t, 3, published)
+	assert.Equal(t, 1, finished)
+
+	app.eventHandler(models.EventRunSuccess, map[string]interface{}{"adapter_id": "first", "job_name": "Write"})
+	app.eventHandler(models.EventJobFinished, map[string]interface{}{"adapter_id": "first"})
+	assert.Equal(t, 0, len(app.exitCh))
+
+	app.eventHandler(models.EventRunSuccess, map[string]interface{}{"adapter_id": "second", "job_name": "Write"})
+	app.eventHandler(models.EventJobFinished, map[string]interface{}{"adapter_id": "second"})
+	select {
+	case <-app.exitCh:
+	case <-time.After(time.Second):
+		t.Error("Exit haven't been received")
+	}
+
+	out := captureStdout(t, app.printSummary)
+	assert.Equal(t, "Summary:\n"+
+		"ADAPTER   JOB    SUCCEEDED  FAILED\n"+
+		"Reader 1  Write  1          0\n"+
+		"Reader 2  Write  2          1\n"+
+		"Reader 2: Write step 2 write_ndef: Tag is read only (1 runs)\n"+
+		"Total: 3 runs succeeded, 1 failed, 0 expired, 0 left on 2 adapters\n", out)
+	assert.EqualError(t, app.runsError(), "1 runs failed")
+	assert.Equal(t, models.ExitRunsFailed, ExitCode(app.runsError()))
+}
+
+func Test_eventHandler_expiredJob(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.session.published, app.session.left = 3, 3
+
+	app.eventHandler(models.EventJobSubmitted, map[string]interface{}{"job_id": "job", "repeat": 3})
+	app.eventHandler(models.EventRunSuccess, map[string]interface{}{"job_id": "job", "run_id": "run"})
+	app.eventHandler(models.EventJobFinished, map[string]interface{}{"job_id": "job", "repeat": 3, "success_runs": 1})
+	select {
+	case <-app.exitCh:
+	case <-time.After(time.Second):
+		t.Error("Exit haven't been received")
+	}
+	assert.Equal(t, 0, app.session.left)
+	assert.Equal(t, 2, app.session.expired)
+
+	err := app.runsError()
+	assert.EqualError(t, err, "Jobs expired with 2 runs left")
+	assert.Equal(t, models.ExitTimeout, ExitCode(err))
+}
+
+func TestAppService_resyncJobs(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.session = &adapterSession{adapter: apiModels.Adapter{AdapterID: "mocked adapter id"}, published: 1, left: 1}
+	app.session.setJob("mocked job id", false)
+
+	out := captureStdout(t, app.resyncJobs)
+	assert.Contains(t, out, "Synchronized 1 runs finished while server connection was lost\n")
+	assert.Equal(t, 0, app.session.left)
+	assert.True(t, app.session.jobs["mocked job id"])
+	select {
+	case <-app.exitCh:
+	case <-time.After(time.Second):
+		t.Error("Exit haven't been received")
+	}
+
+	// processed runs aren't counted again
+	out = captureStdout(t, app.resyncJobs)
+	assert.Equal(t, "", out)
+}
+
+// deletedJobRepository is a server which removed jobs expired while the connection was lost
+type deletedJobRepository struct {
+	*mock.MockedRepositoryService
+}
+
+func (r deletedJobRepository) GetJob(adapterId, id string, withOutput bool) (apiModels.Job, error) {
+	return apiModels.Job{}, errors.New("Job not found")
+}
+
+func TestAppService_resyncJobs_expired(t *testing.T) {
+	app := New(deletedJobRepository{mock.NewRepositoryService(nil)}, func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.session = &adapterSession{adapter: apiModels.Adapter{AdapterID: "mocked adapter id"}, published: 3, left: 3}
+	app.eventHandler(models.EventJobSubmitted, map[string]interface{}{"job_id": "mocked job id", "repeat": 3})
+
+	captureStdout(t, app.resyncJobs)
+	assert.Equal(t, 1, app.session.succeeded)
+	assert.Equal(t, 2, app.session.expired)
+	assert.Equal(t, 0, app.session.left)
+	select {
+	case <-app.exitCh:
+	case <-time.After(time.Second):
+		t.Error("Exit haven't been received")
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"os"
+	"text/tabwriter"
+)
+
+// jobSummary counts runs of the job and errors of its steps
+type jobSummary struct {
+	id        string
+	name      string
+	succeeded int
+	failed    int
+	// errors are messages of failed steps with a number of runs they failed in
+	errors     []string
+	errorCount map[string]int
+}
+
+type runsTotal struct {
+	succeeded int
+	failed    int
+	expired   int
+	left      int
+}
+
+// jobSummary returns summary of the job of the event adding it in the order of the first event
+func (a *adapterSession) jobSummary(r eventResource) *jobSummary {
+	for _, j := range a.summaries {
+		if j.id == r.JobID {
+			if len(j.name) == 0 {
+				j.name = r.JobName
+			}
+			return j
+		}
+	}
+
+	j := &jobSummary{id: r.JobID, name: r.JobName, errorCount: map[string]int{}}
+	a.summaries = append(a.summaries, j)
+
+	return j
+}
+
+func (a *adapterSession) recordRun(r eventResource, success bool) {
+	j := a.jobSummary(r)
+	if success {
+		j.succeeded++
+		return
+	}
+
+	j.failed++
+	for i, step := range r.Results {
+		if step.Status != apiModels.CommandStatusError.String() {
+			continue
+		}
+		msg := fmt.Sprintf("step %d %s: %s", i+1, step.Command, step.Message)
+		if j.errorCount[msg] == 0 {
+			j.errors = append(j.errors, msg)
+		}
+		j.errorCount[msg]++
+	}
+}
+
+func (s *appService) runsTotal() runsTotal {
+	var t runsTotal
+	for _, session := range s.allSessions() {
+		t.succeeded += session.succeeded
+		t.failed += session.failed
+		t.expired += session.expired
+		t.left += session.left
+	}
+
+	return t
+}
+
+// allSessions returns sessions of all adapters of the command, the session of the command adapter is returned
+// when adapters aren't resolved yet
+func (s *appService) allSessions() []*adapterSession {
+	if len(s.sessions) == 0 {
+		return []*adapterSession{s.session}
+	}
+
+	return s.sessions
+}
+
+// printSummary prints table of runs of every job and messages of failed steps
+func (s *appService) printSummary() {
+	multi := s.multiAdapter()
+	fmt.Println("Summary:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if multi {
+		fmt.Fprint(w, "ADAPTER\t")
+	}
+	fmt.Fprintln(w, "JOB\tSUCCEEDED\tFAILED")
+	for _, session := range s.allSessions() {
+		for _, j := range session.summaries {
+			if multi {
+				fmt.Fprintf(w, "%s\t", session.adapter.Name)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\n", j.name, j.succeeded, j.failed)
+		}
+	}
+	_ = w.Flush()
+
+	for _, session := range s.allSessions() {
+		for _, j := range session.summaries {
+			for _, msg := range j.errors {
+				if multi {
+					fmt.Printf("%s: ", session.adapter.Name)
+				}
+				fmt.Printf("%s %s (%d runs)\n", j.name, msg, j.errorCount[msg])
+			}
+		}
+	}
+
+	t := s.runsTotal()
+	fmt.Printf("Total: %d runs succeeded, %d failed, %d expired, %d left", t.succeeded, t.failed, t.expired, t.left)
+	if multi {
+		fmt.Printf(" on %d adapters", len(s.sessions))
+	}
+	fmt.Println()
+}
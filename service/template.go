@@ -0,0 +1,114 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// placeholderPattern matches {uid}, {counter}, {timestamp}, {random:N} and {sig} or {sig:N} placeholders
+var placeholderPattern = regexp.MustCompile(`\{(uid|counter|timestamp|random:\d+|sig|sig:\d+)\}`)
+
+// tagTemplate renders record fields with placeholders for every written tag
+type tagTemplate struct {
+	// key is HMAC-SHA256 key of the sig placeholder
+	key     []byte
+	counter int
+	now     func() time.Time
+}
+
+func hasPlaceholders(records []ndef.NdefPayload) bool {
+	for _, r := range records {
+		_, fields := ndefPayloadToFields(r)
+		for _, v := range fields {
+			if placeholderPattern.MatchString(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// render replaces placeholders in record fields with values of the tag. Counter is incremented for every rendered tag
+func (t *tagTemplate) render(records []ndef.NdefPayload, uid []byte) ([]ndef.NdefPayload, error) {
+	values := map[string]string{
+		"uid":       fmt.Sprintf("%X", uid),
+		"counter":   strconv.Itoa(t.counter),
+		"timestamp": strconv.FormatInt(t.now().Unix(), 10),
+	}
+	t.counter++
+
+	var err error
+	replace := func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+
+		value, e := t.value(name, uid)
+		if e != nil {
+			err = e
+		}
+		return value
+	}
+
+	res := make([]ndef.NdefPayload, len(records))
+	for i, r := range records {
+		ndefType, fields := ndefPayloadToFields(r)
+		for name, v := range fields {
+			fields[name] = placeholderPattern.ReplaceAllStringFunc(v, replace)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		res[i], err = parseNdefPayload(ndefType, fields)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Record %d", i+1)
+		}
+	}
+
+	return res, nil
+}
+
+// value returns random hex string of N characters for random:N and hex encoded HMAC of the tag UID for sig and sig:N
+func (t *tagTemplate) value(name string, uid []byte) (string, error) {
+	parts := strings.SplitN(name, ":", 2)
+	placeholder, size := parts[0], 2*sha256.Size
+	if len(parts) > 1 {
+		size, _ = strconv.Atoi(parts[1])
+	}
+
+	switch placeholder {
+	case "random":
+		b := make([]byte, (size+1)/2)
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", errors.Wrap(err, "Can't generate random value")
+		}
+		return hex.EncodeToString(b)[:size], nil
+	case "sig":
+		if len(t.key) == 0 {
+			return "", errors.New(fmt.Sprintf("Key of sig placeholder should be set with %s flag", models.FlagSigKey))
+		}
+		mac := hmac.New(sha256.New, t.key)
+		mac.Write(uid)
+		sig := hex.EncodeToString(mac.Sum(nil))
+		if size < len(sig) {
+			sig = sig[:size]
+		}
+		return sig, nil
+	}
+
+	return "", errors.New(fmt.Sprintf("Unknown placeholder {%s}", name))
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-cli/opts"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+	"testing"
+	"time"
+)
+
+func Test_tagTemplate(t *testing.T) {
+	records := []ndef.NdefPayload{
+		&ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/t/{uid}?n={counter}&ts={timestamp}&s={sig:8}"},
+		&ndef.NdefRecordPayloadTypeText{Text: "{random:6}", Lang: "English"},
+	}
+	assert.True(t, hasPlaceholders(records))
+	assert.False(t, hasPlaceholders([]ndef.NdefPayload{&ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/{id}"}}))
+
+	tmpl := &tagTemplate{key: []byte("key"), counter: 10, now: func() time.Time { return time.Unix(1600000000, 0) }}
+	rendered, err := tmpl.render(records, []byte{0x04, 0xA2, 0x3B, 0x12})
+	assert.Nil(t, err)
+	assert.Equal(t, &ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/t/04A23B12?n=10&ts=1600000000&s=122d4b0d"}, rendered[0])
+	assert.Len(t, rendered[1].(*ndef.NdefRecordPayloadTypeText).Text, 6)
+	assert.Equal(t, 11, tmpl.counter)
+
+	_, err = (&tagTemplate{now: time.Now}).render(records, []byte{0x04})
+	assert.EqualError(t, err, "Key of sig placeholder should be set with sig-key flag")
+}
+
+func Test_writeRunHandler_template(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	app := New(rep, func(string) {}, opts.Config{})
+	app.repeat = 1
+
+	records := []ndef.NdefPayload{&ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/t/{uid}"}}
+	w := &writeSession{params: models.GenericJobParams{Repeat: 1}, records: records, template: &tagTemplate{now: time.Now}}
+	handler := app.writeRunHandler(w)
+
+	out := captureStdout(t, func() {
+		app.addTagWriteJob(w, app.session, nil, records)
+	})
+	assert.Equal(t, "Writing tag 04 A2 3B 12: https://tagl.me/t/04A23B12\n", out)
+	assert.Equal(t, []byte{0x04, 0xA2, 0x3B, 0x12}, w.renderedUid)
+
+	// tag was swapped after UID was requested
+	out = captureStdout(t, func() {
+		handler(app.session, writeRunTestData(t, []ndefconv.NdefRecord{}, apiModels.CommandWriteNdef))
+	})
+	assert.Contains(t, out, "Tag 04 A2 B3 C4 D5 E6 F7 is mis-written with message rendered for tag 04 A2 3B 12")
+	assert.Equal(t, 1, app.failedRuns)
+	assert.Equal(t, 1, app.misWrittenTags)
+	assert.EqualError(t, app.runsError(), "1 tags are mis-written with records rendered for other tags")
+	assert.Equal(t, []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, w.lastUid)
+}
+
+func Test_addTagWriteJob_failed(t *testing.T) {
+	app := New(mock.NewRepositoryService(nil), func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.repeat = 1
+	app.session.published, app.session.left = 1, 1
+
+	// records can't be rendered without sig key
+	records := []ndef.NdefPayload{&ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/t/{uid}?s={sig:8}"}}
+	w := &writeSession{params: models.GenericJobParams{Repeat: 1}, records: records, template: &tagTemplate{now: time.Now}}
+	out := captureStdout(t, func() {
+		app.addTagWriteJob(w, app.session, nil, records)
+	})
+	assert.Contains(t, out, "Can't add job for tag 04 A2 3B 12: Can't render records: Key of sig placeholder should be set with sig-key flag")
+	assert.Equal(t, 1, app.session.failed)
+	assert.Equal(t, 0, app.session.left)
+	select {
+	case <-app.exitCh:
+	case <-time.After(time.Second):
+		t.Error("Exit haven't been received")
+	}
+}
+
+// swappedTagRepository is an adapter which tag is replaced after UID is requested for the given number of times
+type swappedTagRepository struct {
+	*mock.MockedRepositoryService
+	requests int
+	swapped  int
+	deleted  []string
+}
+
+func (r *swappedTagRepository) GetTags(adapterId string, tagType *apiModels.TagType, withOutput bool) ([]apiModels.Tag, error) {
+	r.requests++
+	if r.requests > r.swapped {
+		return []apiModels.Tag{{Uid: []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}}}, nil
+	}
+
+	return r.MockedRepositoryService.GetTags(adapterId, tagType, withOutput)
+}
+
+func (r *swappedTagRepository) AddWriteJob(p models.GenericJobParams, records []ndef.NdefPayload, protect, verify bool) (*apiModels.Job, *apiModels.NewJob, error) {
+	return &apiModels.Job{JobID: "write job"}, nil, nil
+}
+
+func (r *swappedTagRepository) DeleteJob(adapterId, id string) error {
+	r.deleted = append(r.deleted, id)
+	return nil
+}
+
+func (r *swappedTagRepository) GetRuns(adapterId string, filter client.RunFilter, withOutput bool) ([]apiModels.JobRun, apiModels.PageInfo, error) {
+	return nil, apiModels.PageInfo{}, nil
+}
+
+func Test_addTagWriteJob_swapped(t *testing.T) {
+	records := []ndef.NdefPayload{&ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/t/{uid}"}}
+
+	// the tag is removed before the job is added
+	rep := &swappedTagRepository{MockedRepositoryService: mock.NewRepositoryService(nil), swapped: 1}
+	app := New(rep, func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.repeat = 1
+	app.session.published, app.session.left = 1, 1
+	w := &writeSession{params: models.GenericJobParams{Repeat: 1}, records: records, template: &tagTemplate{now: time.Now}}
+	out := captureStdout(t, func() {
+		app.addTagWriteJob(w, app.session, nil, records)
+	})
+	assert.Contains(t, out, "Can't add job for tag 04 A2 3B 12: Tag is removed from the field before the write job is added")
+	assert.Nil(t, rep.deleted)
+	assert.Equal(t, 1, app.session.failed)
+
+	// the tag is removed after the job is added
+	rep = &swappedTagRepository{MockedRepositoryService: mock.NewRepositoryService(nil), swapped: 2}
+	app = New(rep, func(string) {}, opts.Config{})
+	app.exitCh = make(chan struct{}, 1)
+	app.repeat = 1
+	app.session.published, app.session.left = 1, 1
+	w = &writeSession{params: models.GenericJobParams{Repeat: 1}, records: records, template: &tagTemplate{now: time.Now}}
+	out = captureStdout(t, func() {
+		app.addTagWriteJob(w, app.session, nil, records)
+	})
+	assert.Contains(t, out, "Can't add job for tag 04 A2 3B 12: Tag is removed from the field before it is written, the write job is deleted")
+	assert.Equal(t, []string{"write job"}, rep.deleted)
+	assert.Equal(t, 1, app.session.failed)
+	assert.Equal(t, 0, app.session.left)
+}
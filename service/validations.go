@@ -11,16 +11,30 @@ import (
 	"strings"
 )
 
+// ndefFieldError is returned by NDEF payload validators and points to the invalid field by its flag name
+type ndefFieldError struct {
+	field models.Flag
+	err   error
+}
+
+func (e *ndefFieldError) Error() string {
+	return e.err.Error()
+}
+
+func fieldError(field models.Flag, err error) error {
+	return &ndefFieldError{field: field, err: err}
+}
+
 func validateNdefRecordPayloadRaw(tnf int, t, id, payload string) (*ndef.NdefRecordPayloadRaw, error) {
 	if tnf < 0 || tnf > 6 {
-		return nil, errors.New("Wrong tnf flag value. Can be only from 0 to 6")
+		return nil, fieldError(models.FlagNdefTypeRawTnf, errors.New("Wrong tnf flag value. Can be only from 0 to 6"))
 	}
 	if len(payload) < 1 {
-		return nil, errors.New("Payload value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeRawPayload, errors.New("Payload value can't be empty"))
 	}
 	p, err := utils.ParseHexString(payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "Can't parse payload. It should be HEX string i.e. \"03 AD F3 41\"")
+		return nil, fieldError(models.FlagNdefTypeRawPayload, errors.Wrap(err, "Can't parse payload. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	return &ndef.NdefRecordPayloadRaw{
@@ -33,7 +47,7 @@ func validateNdefRecordPayloadRaw(tnf int, t, id, payload string) (*ndef.NdefRec
 
 func validateNdefRecordPayloadUrl(url string) (*ndef.NdefRecordPayloadUrl, error) {
 	if len(url) == 0 {
-		return nil, errors.New("Url flag can't be empty.")
+		return nil, fieldError(models.FlagNdefTypeUrl, errors.New("Url flag can't be empty."))
 	}
 
 	matched, err := regexp.MatchString(`http(s)?\:\/\/\w+.*`, url)
@@ -41,7 +55,7 @@ func validateNdefRecordPayloadUrl(url string) (*ndef.NdefRecordPayloadUrl, error
 		return nil, errors.Wrap(err, "Error on the url match string")
 	}
 	if !matched {
-		return nil, errors.New("Url has wrong value. It must contain http or https and url origin shouldn't be empty.")
+		return nil, fieldError(models.FlagNdefTypeUrl, errors.New("Url has wrong value. It must contain http or https and url origin shouldn't be empty."))
 	}
 
 	return &ndef.NdefRecordPayloadUrl{
@@ -51,7 +65,7 @@ func validateNdefRecordPayloadUrl(url string) (*ndef.NdefRecordPayloadUrl, error
 
 func validateNdefRecordPayloadUri(uri string) (*ndef.NdefRecordPayloadUri, error) {
 	if len(uri) < 1 {
-		return nil, errors.New("URI value can't be empty.")
+		return nil, fieldError(models.FlagNdefUri, errors.New("URI value can't be empty."))
 	}
 
 	return &ndef.NdefRecordPayloadUri{
@@ -61,12 +75,12 @@ func validateNdefRecordPayloadUri(uri string) (*ndef.NdefRecordPayloadUri, error
 
 func validateNdefRecordPayloadTypeText(text, lang string) (*ndef.NdefRecordPayloadTypeText, error) {
 	if len(text) < 1 {
-		return nil, errors.New("Text value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeText, errors.New("Text value can't be empty"))
 	}
 
 	ok := models.NdefLangValues.Contains(lang)
 	if !ok {
-		return nil, errors.New(fmt.Sprintf("Lang value must be one of the following falues: %s", models.NdefLangValues))
+		return nil, fieldError(models.FlagNdefTypeLang, errors.New(fmt.Sprintf("Lang value must be one of the following falues: %s", models.NdefLangValues)))
 	}
 
 	return &ndef.NdefRecordPayloadTypeText{
@@ -77,15 +91,15 @@ func validateNdefRecordPayloadTypeText(text, lang string) (*ndef.NdefRecordPaylo
 
 func validateNdefTypeVcard(postal, email, fName string) (*ndef.NdefRecordPayloadVcard, error) {
 	if len(postal) > 0 && !regexp.MustCompile(`^\d+$`).MatchString(postal) {
-		return nil, errors.New("Flag address-postal-code should contain only digits.")
+		return nil, fieldError(models.FlagNdefTypeVcardAddressPostalCode, errors.New("Flag address-postal-code should contain only digits."))
 	}
 
 	if len(email) > 0 && !utils.ValidateEmail(email) {
-		return nil, errors.New("Flag email should contain valid email.")
+		return nil, fieldError(models.FlagNdefTypeVcardEmail, errors.New("Flag email should contain valid email."))
 	}
 
 	if len(fName) == 0 {
-		return nil, errors.New("Flag first-name can't be empty.")
+		return nil, fieldError(models.FlagNdefTypeVcardFirstName, errors.New("Flag first-name can't be empty."))
 	}
 
 	return &ndef.NdefRecordPayloadVcard{
@@ -98,19 +112,19 @@ func validateNdefTypeVcard(postal, email, fName string) (*ndef.NdefRecordPayload
 
 func validateNdefRecordPayloadMime(t, format, content string) (*ndef.NdefRecordPayloadMime, error) {
 	if len(t) < 1 {
-		return nil, errors.New("Type value can't be empty.")
+		return nil, fieldError(models.FlagNdefTypeType, errors.New("Type value can't be empty."))
 	}
 	res := ndef.NdefRecordPayloadMime{}
 	res.Type = t
 
 	mimeFormat, err := models.StringToMimeFormat(format)
 	if err != nil {
-		return nil, errors.Wrap(err, "Format can be either \"hex\" or \"ascii\"")
+		return nil, fieldError(models.FlagNdefTypeMimeFormat, errors.Wrap(err, "Format can be either \"hex\" or \"ascii\""))
 	}
 	res.Format = mimeFormat
 
 	if len(content) < 1 {
-		return nil, errors.New("Content value can't be empty.")
+		return nil, fieldError(models.FlagNdefTypeMimeContent, errors.New("Content value can't be empty."))
 	}
 
 	if mimeFormat == models.MimeFormatASCII {
@@ -120,7 +134,7 @@ func validateNdefRecordPayloadMime(t, format, content string) (*ndef.NdefRecordP
 
 	c, err := utils.ParseHexString(content)
 	if err != nil {
-		return nil, errors.Wrap(err, "Can't parse content string. It should be HEX string i.e. \"03 AD F3 41\"")
+		return nil, fieldError(models.FlagNdefTypeMimeContent, errors.Wrap(err, "Can't parse content string. It should be HEX string i.e. \"03 AD F3 41\""))
 	}
 
 	res.ContentHEX = c
@@ -130,7 +144,7 @@ func validateNdefRecordPayloadMime(t, format, content string) (*ndef.NdefRecordP
 
 func validateNdefRecordPayloadPhone(p string) (*ndef.NdefRecordPayloadPhone, error) {
 	if len(p) < 1 {
-		return nil, errors.New("Phone number value can't be empty")
+		return nil, fieldError(models.FlagNdefTypePhone, errors.New("Phone number value can't be empty"))
 	}
 
 	return &ndef.NdefRecordPayloadPhone{
@@ -140,29 +154,29 @@ func validateNdefRecordPayloadPhone(p string) (*ndef.NdefRecordPayloadPhone, err
 
 func validateNdefRecordPayloadGeo(lat, lon string) (*ndef.NdefRecordPayloadGeo, error) {
 	if len(lat) < 1 {
-		return nil, errors.New("Latitude value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeGeoLat, errors.New("Latitude value can't be empty"))
 	}
 	lat = strings.Replace(lat, ",", ".", -1)
 	latF, err := strconv.ParseFloat(lat, 16)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error on parsing float from latitude string")
+		return nil, fieldError(models.FlagNdefTypeGeoLat, errors.Wrap(err, "Error on parsing float from latitude string"))
 	}
 
 	if latF < -90 || latF > 90 {
-		return nil, errors.New("Wrong latitude value – can be from -90 to 90")
+		return nil, fieldError(models.FlagNdefTypeGeoLat, errors.New("Wrong latitude value – can be from -90 to 90"))
 	}
 
 	if len(lon) < 1 {
-		return nil, errors.New("Longitude value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeGeoLon, errors.New("Longitude value can't be empty"))
 	}
 	lon = strings.Replace(lon, ",", ".", -1)
 	lonF, err := strconv.ParseFloat(lon, 16)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error on parsing float from longitude string")
+		return nil, fieldError(models.FlagNdefTypeGeoLon, errors.Wrap(err, "Error on parsing float from longitude string"))
 	}
 
 	if lonF < -180 || lonF > 180 {
-		return nil, errors.New("Wrong latitude value – can be from -180 to 180")
+		return nil, fieldError(models.FlagNdefTypeGeoLon, errors.New("Wrong latitude value – can be from -180 to 180"))
 	}
 
 	return &ndef.NdefRecordPayloadGeo{
@@ -173,7 +187,7 @@ func validateNdefRecordPayloadGeo(lat, lon string) (*ndef.NdefRecordPayloadGeo,
 
 func validateNdefRecordPayloadAar(p string) (*ndef.NdefRecordPayloadAar, error) {
 	if len(p) < 1 {
-		return nil, errors.New("Package name value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeAarPackage, errors.New("Package name value can't be empty"))
 	}
 
 	return &ndef.NdefRecordPayloadAar{
@@ -183,11 +197,11 @@ func validateNdefRecordPayloadAar(p string) (*ndef.NdefRecordPayloadAar, error)
 
 func validateNdefRecordPayloadPoster(title, uri string) (*ndef.NdefRecordPayloadPoster, error) {
 	if len(title) < 1 {
-		return nil, errors.New("Title value can't be empty")
+		return nil, fieldError(models.FlagNdefTypeTitle, errors.New("Title value can't be empty"))
 	}
 
 	if len(uri) < 1 {
-		return nil, errors.New("URI value can't be empty")
+		return nil, fieldError(models.FlagNdefUri, errors.New("URI value can't be empty"))
 	}
 
 	return &ndef.NdefRecordPayloadPoster{
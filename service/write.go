@@ -0,0 +1,349 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-goclient/pkg/client"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+	"log"
+	"strings"
+)
+
+// ndefRun is a job run of the write job with read back message
+type ndefRun struct {
+	Uid []byte
+	// Ndef is nil when the run doesn't have read NDEF step
+	Ndef   *ndefconv.Ndef
+	Locked bool
+}
+
+func parseNdefRun(data interface{}) (ndefRun, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return ndefRun{}, errors.Wrap(err, "Can't encode job run")
+	}
+
+	var resource struct {
+		Tag struct {
+			Uid string `json:"uid"`
+		} `json:"tag"`
+		Results []struct {
+			Command string          `json:"command"`
+			Output  json.RawMessage `json:"output"`
+		} `json:"results"`
+	}
+	err = json.Unmarshal(encoded, &resource)
+	if err != nil {
+		return ndefRun{}, errors.Wrap(err, "Can't parse job run")
+	}
+
+	run := ndefRun{}
+	run.Uid, err = base64.StdEncoding.DecodeString(resource.Tag.Uid)
+	if err != nil {
+		return ndefRun{}, errors.Wrap(err, "Can't decode tag UID. It should be base64 encoded")
+	}
+
+	for _, r := range resource.Results {
+		switch r.Command {
+		case apiModels.CommandLockPermanent.String():
+			run.Locked = true
+		case apiModels.CommandReadNdef.String():
+			var output apiModels.ReadNdefOutputResource
+			err = json.Unmarshal(r.Output, &output)
+			if err != nil {
+				return ndefRun{}, errors.Wrap(err, "Can't parse read NDEF output")
+			}
+			message, err := output.Ndef.ToNdefRecord()
+			if err != nil {
+				return ndefRun{}, errors.Wrap(err, "Can't parse read NDEF output")
+			}
+			run.Ndef = &message
+		}
+	}
+
+	return run, nil
+}
+
+// writeSession is a write command run. When tags are written with batch rows or per-tag records, every tag
+// is written by separate job with single run, the next job is added when the previous tag is done
+type writeSession struct {
+	params  models.GenericJobParams
+	records []ndef.NdefPayload
+	verify  bool
+	// protect is set when lock step is added to the write job. With verify the tag is locked after read back,
+	// the server can't skip the lock step, so the tag which doesn't match the written message is locked too
+	protect bool
+	// rows are batch file rows to write, batch is not used when rows are nil
+	rows        []batchRow
+	resultsFile string
+	// template renders records for every tag, the tag UID is requested before the write job is added
+	template *tagTemplate
+	// deriveAuth is set when auth password is derived from the tag UID
+	deriveAuth  bool
+	rendered    []ndef.NdefPayload
+	renderedUid []byte
+	lastUid     []byte
+	// current is a number of the tag being written
+	current int
+}
+
+func (w *writeSession) chained() bool {
+	return w.rows != nil || w.perTag()
+}
+
+// perTag is set when write job depends on UID of the tag in the field
+func (w *writeSession) perTag() bool {
+	return w.template != nil || w.deriveAuth
+}
+
+func (w *writeSession) total(repeat int) int {
+	if w.rows != nil {
+		return len(w.rows)
+	}
+
+	return repeat
+}
+
+// tagRecords returns records written to the current tag
+func (w *writeSession) tagRecords() []ndef.NdefPayload {
+	if w.template != nil {
+		return w.rendered
+	}
+
+	return w.sourceRecords()
+}
+
+// sourceRecords returns records of the current tag before placeholders are rendered
+func (w *writeSession) sourceRecords() []ndef.NdefPayload {
+	if w.rows != nil {
+		return w.rows[w.current].Records
+	}
+
+	return w.records
+}
+
+// writeRunHandler processes runs of write session jobs. Written message is compared with the message read back
+// at the end of the job, rows of the batch are recorded to results file
+func (s *appService) writeRunHandler(w *writeSession) func(*adapterSession, interface{}) {
+	return func(session *adapterSession, data interface{}) {
+		run, err := parseNdefRun(data)
+		if err != nil {
+			log.Println("Can't process written tag: ", err)
+		}
+
+		verified := err == nil
+		previous := w.lastUid
+		w.lastUid = run.Uid
+		if verified && w.rows != nil && w.current > 0 && bytes.Equal(run.Uid, previous) {
+			fmt.Println(color.RedString("Tag % X is already written with the previous row", run.Uid))
+			verified = false
+		}
+		// the job isn't bound to the tag, so another tag can be written with the message of the tag in the field
+		misWritten := verified && w.perTag() && !bytes.Equal(run.Uid, w.renderedUid)
+		if misWritten {
+			fmt.Println(color.RedString("Tag % X is mis-written with message rendered for tag % X", run.Uid, w.renderedUid))
+			s.misWrittenTags++
+			verified = false
+		}
+
+		if w.verify {
+			var mismatches []ndef.RecordMismatch
+			if verified && run.Ndef == nil {
+				log.Println("Can't verify written tag: Job run doesn't contain read NDEF output")
+				verified = false
+			} else if verified {
+				mismatches = ndef.CompareMessage(w.tagRecords(), run.Ndef.Message)
+				verified = len(mismatches) == 0
+			}
+			s.printWriteVerification(run.Uid, len(w.tagRecords()), verified, mismatches)
+		} else if s.isJsonOutput() {
+			s.printJsonItem(data)
+		}
+		if !verified {
+			s.failedRuns++
+			if run.Locked && !s.isJsonOutput() {
+				fmt.Println(color.RedString("Tag % X is locked though it isn't verified", run.Uid))
+			}
+		}
+
+		if w.rows != nil {
+			status := batchStatusWritten
+			if misWritten {
+				status = batchStatusMisWritten
+			} else if !verified {
+				status = batchStatusFailed
+			}
+			s.recordBatchRow(w, run.Uid, status)
+		}
+
+		s.addNextWrite(w, session)
+	}
+}
+
+// addNextWrite adds the job for the next tag of chained write session. Session mutex should be locked
+func (s *appService) addNextWrite(w *writeSession, session *adapterSession) {
+	w.current++
+	if !w.chained() || w.current >= w.total(s.repeat) {
+		return
+	}
+	// the written tag is still in the field, so the next job is added for another tag
+	go s.addTagWriteJob(w, session, w.lastUid, w.sourceRecords())
+}
+
+// addTagWriteJob waits for a tag other than the previous one and adds the job writing records. Records are rendered
+// and auth password is derived for the tag UID when they depend on it
+func (s *appService) addTagWriteJob(w *writeSession, session *adapterSession, previous []byte, records []ndef.NdefPayload) {
+	tag, err := s.waitForNewTag(s.sessionCtx, w.params.AdapterId, previous)
+	if err != nil {
+		return
+	}
+	uid := tag.Uid
+
+	p := w.params
+	if w.deriveAuth {
+		pwd, err := s.derivePassword(uid)
+		if err != nil {
+			s.failWriteTag(w, session, uid, errors.Wrap(err, "Can't derive password"))
+			return
+		}
+		p.Auth = pwd.Pwd
+	}
+
+	var rendered []ndef.NdefPayload
+	if w.template != nil {
+		rendered, err = w.template.render(records, uid)
+		if err != nil {
+			s.failWriteTag(w, session, uid, errors.Wrap(err, "Can't render records"))
+			return
+		}
+		records = rendered
+
+		if !s.isJsonOutput() {
+			var values []string
+			for _, r := range rendered {
+				values = append(values, r.ToRecord().Data.String())
+			}
+			fmt.Printf("Writing tag % X: %s\n", uid, strings.Join(values, ", "))
+		}
+	}
+
+	s.sessionMutex.Lock()
+	w.rendered = rendered
+	w.renderedUid = uid
+	s.sessionMutex.Unlock()
+
+	// the server can't bind the job to the tag UID, so the job is added only while the tag is in the field
+	// and deleted when the tag is removed before it is written. Tags swapped after that are reported as mis-written
+	if !s.tagInField(w.params.AdapterId, uid) {
+		s.failWriteTag(w, session, uid, errors.New("Tag is removed from the field before the write job is added"))
+		return
+	}
+	job, _, err := s.repository.AddWriteJob(p, records, w.protect, w.verify)
+	if err != nil {
+		s.failWriteTag(w, session, uid, errors.Wrap(err, "Can't add write job"))
+		return
+	}
+	if !s.tagInField(w.params.AdapterId, uid) && s.deleteUnrunJob(w.params.AdapterId, job.JobID) {
+		s.failWriteTag(w, session, uid, errors.New("Tag is removed from the field before it is written, the write job is deleted"))
+	}
+}
+
+// tagInField reports whether the tag is in the field of the adapter
+func (s *appService) tagInField(adapterId string, uid []byte) bool {
+	tags, err := s.repository.GetTags(adapterId, nil, false)
+	if err != nil {
+		log.Println("Can't get tags: ", err)
+		return false
+	}
+	for _, t := range tags {
+		if bytes.Equal(t.Uid, uid) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteUnrunJob deletes the job and reports whether it is deleted before any runs. Runs of the job which is
+// already run are processed by events
+func (s *appService) deleteUnrunJob(adapterId, jobId string) bool {
+	err := s.repository.DeleteJob(adapterId, jobId)
+	if err != nil {
+		log.Println("Can't delete write job: ", err)
+		return false
+	}
+
+	runs, _, err := s.repository.GetRuns(adapterId, client.RunFilter{JobID: &jobId}, false)
+	if err != nil {
+		log.Println("Can't get runs of write job: ", err)
+		return false
+	}
+
+	return len(runs) == 0
+}
+
+// failWriteTag counts the tag which can't be written as failed and adds the job for the next tag
+func (s *appService) failWriteTag(w *writeSession, session *adapterSession, uid []byte, err error) {
+	s.sessionMutex.Lock()
+	defer s.sessionMutex.Unlock()
+
+	w.lastUid = uid
+	if w.rows != nil {
+		s.recordBatchRow(w, uid, batchStatusFailed)
+	}
+	if s.failTag(session, uid, err) {
+		s.addNextWrite(w, session)
+	}
+}
+
+func (s *appService) recordBatchRow(w *writeSession, uid []byte, status string) {
+	row := w.rows[w.current].Row
+	err := writeBatchResult(w.resultsFile, row, uid, status)
+	if err != nil {
+		log.Println("Can't write batch results: ", err)
+	}
+
+	if !s.isJsonOutput() {
+		fmt.Printf("Row %d (%d of %d): tag % X %s\n", row, w.current+1, len(w.rows), uid, status)
+	}
+}
+
+func (s *appService) printWriteVerification(uid []byte, records int, verified bool, mismatches []ndef.RecordMismatch) {
+	if s.isJsonOutput() {
+		out := models.WriteVerificationOutput{
+			Uid:        fmt.Sprintf("% X", uid),
+			Records:    records,
+			Verified:   verified,
+			Mismatches: []models.NdefRecordMismatchOutput{},
+		}
+		for _, m := range mismatches {
+			out.Mismatches = append(out.Mismatches, models.NdefRecordMismatchOutput{Record: m.Index + 1, Expected: m.Expected, Actual: m.Actual})
+		}
+		s.printJsonItem(out)
+		return
+	}
+
+	if verified {
+		fmt.Println(color.GreenString("Verification: %d records of tag % X match the written message", records, uid))
+		return
+	}
+
+	fmt.Println(color.RedString("Verification failed: tag % X doesn't match the written message", uid))
+	for _, m := range mismatches {
+		expected, actual := m.Expected, m.Actual
+		if len(expected) == 0 {
+			expected = "no record"
+		}
+		if len(actual) == 0 {
+			actual = "no record"
+		}
+		fmt.Printf("Record %d: written %s, read %s\n", m.Index+1, expected, actual)
+	}
+}
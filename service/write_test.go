@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/taglme/nfc-cli/mock"
+	"github.com/taglme/nfc-cli/models"
+	"github.com/taglme/nfc-cli/ndef"
+	"github.com/taglme/nfc-cli/opts"
+	apiModels "github.com/taglme/nfc-goclient/pkg/models"
+	"github.com/taglme/nfc-goclient/pkg/ndefconv"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func writeRunTestData(t *testing.T, message []ndefconv.NdefRecord, commands ...apiModels.Command) interface{} {
+	return tagWriteRunTestData(t, []byte{0x04, 0xA2, 0xB3, 0xC4, 0xD5, 0xE6, 0xF7}, message, commands...)
+}
+
+func tagWriteRunTestData(t *testing.T, uid []byte, message []ndefconv.NdefRecord, commands ...apiModels.Command) interface{} {
+	run := apiModels.JobRunResource{
+		Tag: apiModels.TagResource{Uid: base64.StdEncoding.EncodeToString(uid)},
+	}
+	for _, c := range commands {
+		result := apiModels.StepResultResource{Command: c.String(), Status: "success"}
+		if c == apiModels.CommandReadNdef {
+			result.Output = apiModels.ReadNdefOutput{Ndef: ndefconv.Ndef{Message: message}}.ToResource()
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoded, err := json.Marshal(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var data interface{}
+	if err = json.Unmarshal(encoded, &data); err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func captureStdout(t *testing.T, f func()) string {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	f()
+	w.Close()
+	os.Stdout = stdout
+	out, _ := ioutil.ReadAll(r)
+
+	return string(out)
+}
+
+func Test_writeRunHandler(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	app := New(rep, func(string) {}, opts.Config{})
+	app.repeat = 2
+
+	records := []ndef.NdefPayload{ndef.NdefRecordPayloadUrl{Url: "https://tagl.me"}}
+	message := []ndefconv.NdefRecord{records[0].ToRecord()}
+	handler := app.writeRunHandler(&writeSession{params: models.GenericJobParams{Repeat: 2}, records: records, verify: true, protect: true})
+
+	out := captureStdout(t, func() {
+		handler(app.session, writeRunTestData(t, message, apiModels.CommandWriteNdef, apiModels.CommandReadNdef, apiModels.CommandLockPermanent))
+	})
+	assert.Contains(t, out, "Verification: 1 records of tag 04 A2 B3 C4 D5 E6 F7 match the written message")
+	assert.NotContains(t, out, "locked")
+	assert.Equal(t, 0, app.failedRuns)
+
+	wrong := ndef.NdefRecordPayloadUrl{Url: "https://tagl"}.ToRecord()
+	out = captureStdout(t, func() {
+		handler(app.session, writeRunTestData(t, []ndefconv.NdefRecord{wrong}, apiModels.CommandWriteNdef, apiModels.CommandReadNdef, apiModels.CommandLockPermanent))
+	})
+	assert.Contains(t, out, "Verification failed: tag 04 A2 B3 C4 D5 E6 F7 doesn't match the written message")
+	assert.Contains(t, out, "Record 1: written https://tagl.me (url), read https://tagl (url)")
+	// lock step of the same job is run by the server regardless of verification
+	assert.Contains(t, out, "Tag 04 A2 B3 C4 D5 E6 F7 is locked though it isn't verified")
+	assert.Equal(t, 1, app.failedRuns)
+}
+
+func Test_writeRunHandler_batch(t *testing.T) {
+	rep := mock.NewRepositoryService(nil)
+	app := New(rep, func(string) {}, opts.Config{})
+	defer os.Remove("batch_test.results.csv")
+
+	rows := []batchRow{
+		{Row: 2, Records: []ndef.NdefPayload{ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/2"}}},
+		{Row: 3, Records: []ndef.NdefPayload{ndef.NdefRecordPayloadUrl{Url: "https://tagl.me/3"}}},
+	}
+	handler := app.writeRunHandler(&writeSession{params: models.GenericJobParams{Repeat: 1}, verify: true, rows: rows, resultsFile: "batch_test.results.csv"})
+
+	out := captureStdout(t, func() {
+		handler(app.session, writeRunTestData(t, []ndefconv.NdefRecord{rows[0].Records[0].ToRecord()}, apiModels.CommandWriteNdef, apiModels.CommandReadNdef))
+		// the tag of the previous row is written again
+		handler(app.session, writeRunTestData(t, []ndefconv.NdefRecord{rows[1].Records[0].ToRecord()}, apiModels.CommandWriteNdef, apiModels.CommandReadNdef))
+	})
+	assert.Contains(t, out, "Row 2 (1 of 2): tag 04 A2 B3 C4 D5 E6 F7 written")
+	assert.Contains(t, out, "Tag 04 A2 B3 C4 D5 E6 F7 is already written with the previous row")
+	assert.Contains(t, out, "Row 3 (2 of 2): tag 04 A2 B3 C4 D5 E6 F7 failed")
+
+	results, err := ioutil.ReadFile("batch_test.results.csv")
+	assert.Nil(t, err)
+	assert.Contains(t, string(results), "row,uid,status,time\n2,04A2B3C4D5E6F7,written,")
+	assert.Contains(t, string(results), "\n3,04A2B3C4D5E6F7,failed,")
+}
@@ -2,13 +2,19 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/pkg/errors"
+	"github.com/taglme/nfc-cli/dump"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 func (s *appService) writeToFile(filename string, data interface{}) (err error) {
 	var file *os.File
-	if s.append || (s.ongoingJobs.published > 1 && s.ongoingJobs.published-s.ongoingJobs.left > 1) {
+	published, finished := s.runsProgress()
+	if s.append || (published > 1 && finished > 1) {
 		file, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, os.ModePerm)
 	} else {
 		file, err = os.Create(filename)
@@ -27,3 +33,39 @@ func (s *appService) writeToFile(filename string, data interface{}) (err error)
 
 	return nil
 }
+
+// writeDumpToFile writes dump of the job run in the dump format. As dump files can't be appended,
+// dumps of repeated runs are written to separate files with the run number
+func (s *appService) writeDumpToFile(filename string, data interface{}) error {
+	d, err := dump.FromRunData(data)
+	if err != nil {
+		return err
+	}
+
+	if published, finished := s.runsProgress(); published > 1 {
+		filename = numberedFilename(filename, finished)
+	}
+
+	return writeDump(filename, d, s.dumpFormat)
+}
+
+func writeDump(filename string, d dump.Dump, format dump.Format) error {
+	encoded, err := dump.Encode(d, format)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(filename, encoded, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Can't write dump file")
+	}
+
+	return nil
+}
+
+// numberedFilename inserts number before the file extension: dump.bin -> dump-2.bin
+func numberedFilename(filename string, n int) string {
+	ext := filepath.Ext(filename)
+
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(filename, ext), n, ext)
+}
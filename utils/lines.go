@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"gopkg.in/yaml.v3"
+)
+
+// SourceLines locates values of YAML or JSON document in its source
+type SourceLines struct {
+	root *yaml.Node
+}
+
+// NewSourceLines parses the document. Nothing is located in documents which can't be parsed
+func NewSourceLines(data []byte) SourceLines {
+	var doc yaml.Node
+	if yaml.Unmarshal(data, &doc) != nil || len(doc.Content) == 0 {
+		return SourceLines{}
+	}
+
+	return SourceLines{root: doc.Content[0]}
+}
+
+// Line returns line of the value at the path of map keys and sequence indexes, i.e. "jobs", 1, "steps", 0.
+// Line of map key is returned for map values. When the value can't be located line of its closest located
+// parent is returned, zero is returned when the first element of the path can't be located
+func (l SourceLines) Line(path ...interface{}) int {
+	line := 0
+	node := l.root
+	for _, p := range path {
+		if node == nil {
+			break
+		}
+
+		var next *yaml.Node
+		switch key := p.(type) {
+		case int:
+			if node.Kind == yaml.SequenceNode && key >= 0 && key < len(node.Content) {
+				next = node.Content[key]
+				line = next.Line
+			}
+		case string:
+			if node.Kind == yaml.MappingNode {
+				for i := 0; i+1 < len(node.Content); i += 2 {
+					if node.Content[i].Value == key {
+						next = node.Content[i+1]
+						line = node.Content[i].Line
+						break
+					}
+				}
+			}
+		}
+		node = next
+	}
+
+	return line
+}
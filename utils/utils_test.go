@@ -32,3 +32,18 @@ func TestValidateEmail(t *testing.T) {
 	assert.True(t, ValidateEmail("email@gmail.com"))
 	assert.False(t, ValidateEmail("email"))
 }
+
+func TestSourceLines_Line(t *testing.T) {
+	yamlLines := NewSourceLines([]byte("jobs:\n  - name: a\n    steps:\n      - command: read\n\n      - command: write\n"))
+	assert.Equal(t, 2, yamlLines.Line("jobs", 0))
+	assert.Equal(t, 6, yamlLines.Line("jobs", 0, "steps", 1))
+	assert.Equal(t, 3, yamlLines.Line("jobs", 0, "steps", 2))
+	assert.Equal(t, 0, yamlLines.Line("other"))
+
+	jsonLines := NewSourceLines([]byte("[\n  {\n    \"ndef-type\": \"url\",\n    \"url\": \"x\"\n  }\n]"))
+	assert.Equal(t, 2, jsonLines.Line(0))
+	assert.Equal(t, 4, jsonLines.Line(0, "url"))
+	assert.Equal(t, 0, jsonLines.Line(1))
+
+	assert.Equal(t, 0, NewSourceLines([]byte("{")).Line(0))
+}